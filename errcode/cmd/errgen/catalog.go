@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog declarative definition of the business errors of a namespace
+type Catalog struct {
+	// Package package of the generated code
+	Package string `toml:"package" yaml:"package" json:"package"`
+
+	// Namespace namespace owning the business errors
+	Namespace Namespace `toml:"namespace" yaml:"namespace" json:"namespace"`
+
+	// Errors business errors
+	Errors []Error `toml:"errors" yaml:"errors" json:"errors"`
+}
+
+// Namespace namespace reserving a code range
+type Namespace struct {
+	Name string `toml:"name" yaml:"name" json:"name"`
+	Min  uint32 `toml:"min" yaml:"min" json:"min"`
+	Max  uint32 `toml:"max" yaml:"max" json:"max"`
+
+	// Var variable name of the namespace, defaults to the namespace name
+	Var string `toml:"var" yaml:"var" json:"-"`
+}
+
+// Error definition of a business error
+type Error struct {
+	// Name name of the business error, unique within the namespace
+	Name string `toml:"name" yaml:"name" json:"name"`
+
+	// Var variable name of the business error, defaults to Err followed by the name
+	Var string `toml:"var" yaml:"var" json:"-"`
+
+	// Code business status code
+	Code uint32 `toml:"code" yaml:"code" json:"code"`
+
+	// HTTP HTTP status code, derived from the gRPC code if declared, otherwise defaults to 200
+	HTTP int `toml:"http" yaml:"http" json:"http_status"`
+
+	// GRPC gRPC canonical code name, e.g. NotFound, derived from the HTTP status code if not declared
+	GRPC string `toml:"grpc" yaml:"grpc" json:"grpc_code"`
+
+	// Messages messages by locale, the default locale is required
+	Messages map[string]string `toml:"messages" yaml:"messages" json:"messages"`
+
+	// Aliases deprecated variable names kept for compatibility
+	Aliases []string `toml:"aliases" yaml:"aliases" json:"-"`
+
+	// grpcDeclared whether the gRPC code is declared rather than derived from the HTTP status code
+	grpcDeclared bool
+}
+
+// loadCatalog loads the catalog from a TOML or YAML file
+func loadCatalog(filename string) (*Catalog, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{}
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".toml":
+		d := toml.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		err = d.Decode(c)
+	case ".yaml", ".yml":
+		d := yaml.NewDecoder(bytes.NewReader(b))
+		d.KnownFields(true)
+		err = d.Decode(c)
+	default:
+		return nil, fmt.Errorf("unsupported catalog format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filename, err)
+	}
+
+	if err = c.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return c, nil
+}
+
+// normalize fills the defaults and validates the catalog
+func (c *Catalog) normalize() error {
+	if !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("illegal package %q", c.Package)
+	}
+	if c.Namespace.Name == "" || c.Namespace.Min > c.Namespace.Max {
+		return fmt.Errorf("illegal namespace %q [%d, %d]", c.Namespace.Name, c.Namespace.Min, c.Namespace.Max)
+	}
+	if c.Namespace.Var == "" {
+		c.Namespace.Var = c.Namespace.Name
+	}
+	if !token.IsIdentifier(c.Namespace.Var) {
+		return fmt.Errorf("illegal namespace var %q", c.Namespace.Var)
+	}
+	if len(c.Errors) == 0 {
+		return errors.New("no errors defined")
+	}
+
+	codes, names, vars := map[uint32]string{}, map[string]bool{}, map[string]bool{c.Namespace.Var: true}
+	for i := range c.Errors {
+		e := &c.Errors[i]
+		if e.Var == "" {
+			e.Var = "Err" + e.Name
+		}
+
+		if !token.IsIdentifier(e.Name) {
+			return fmt.Errorf("illegal error name %q", e.Name)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("duplicate error name %q", e.Name)
+		}
+		names[e.Name] = true
+		for _, v := range append([]string{e.Var}, e.Aliases...) {
+			if !token.IsIdentifier(v) || vars[v] {
+				return fmt.Errorf("illegal or duplicate var %q of error %q", v, e.Name)
+			}
+			vars[v] = true
+		}
+		if other, ok := codes[e.Code]; ok {
+			return fmt.Errorf("code %d of error %q already used by %q", e.Code, e.Name, other)
+		}
+		codes[e.Code] = e.Name
+		if e.Code < c.Namespace.Min || e.Code > c.Namespace.Max {
+			return fmt.Errorf("code %d of error %q out of namespace range [%d, %d]",
+				e.Code, e.Name, c.Namespace.Min, c.Namespace.Max)
+		}
+		if e.GRPC != "" {
+			gc, ok := grpcCodes[e.GRPC]
+			if !ok {
+				return fmt.Errorf("illegal grpc code %q of error %q", e.GRPC, e.Name)
+			}
+			e.grpcDeclared = true
+			if e.HTTP == 0 {
+				e.HTTP = grpcToHTTP(gc)
+			}
+		} else {
+			if e.HTTP == 0 {
+				e.HTTP = http.StatusOK
+			}
+			e.GRPC = derivedGRPCCode(e.Code, e.HTTP).String()
+		}
+		if http.StatusText(e.HTTP) == "" && e.HTTP != statusClientClosedRequest {
+			return fmt.Errorf("illegal http status %d of error %q", e.HTTP, e.Name)
+		}
+		if e.Messages[defaultLocale] == "" {
+			return fmt.Errorf("missing %s message of error %q", defaultLocale, e.Name)
+		}
+	}
+
+	return nil
+}
+
+// locales returns the locales of the messages, the default locale first
+func (c *Catalog) locales() []string {
+	set := map[string]bool{}
+	for _, e := range c.Errors {
+		for locale := range e.Messages {
+			if locale != defaultLocale {
+				set[locale] = true
+			}
+		}
+	}
+
+	locales := make([]string, 0, len(set))
+	for locale := range set {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return append([]string{defaultLocale}, locales...)
+}
+
+// sorted returns the business errors ordered by code
+func (c *Catalog) sorted() []Error {
+	es := append([]Error(nil), c.Errors...)
+	sort.Slice(es, func(i, j int) bool { return es[i].Code < es[j].Code })
+	return es
+}
+
+// grpcCodes gRPC canonical codes by name
+var grpcCodes = func() map[string]codes.Code {
+	m := make(map[string]codes.Code)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[c.String()] = c
+	}
+
+	return m
+}()
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"cxqi/common/errcode"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	c, err := loadCatalog("../../errors.toml")
+	require.NoError(t, err)
+
+	b, err := generateGo(c, "errors.toml")
+	require.NoError(t, err)
+	want, err := os.ReadFile("../../errors_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(b), "errcode/errors_gen.go is out of date, run go generate")
+
+	want, err = os.ReadFile("../../errors.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(generateMarkdown(c, "errors.toml")), "errcode/errors.md is out of date, run go generate")
+
+	b, err = generateJSON(c)
+	require.NoError(t, err)
+	want, err = os.ReadFile("../../errors.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(b), "errcode/errors.json is out of date, run go generate")
+}
+
+func TestRun_YAML(t *testing.T) {
+	dir := t.TempDir()
+	out, md, js := filepath.Join(dir, "errors_gen.go"), filepath.Join(dir, "errors.md"), filepath.Join(dir, "errors.json")
+	require.NoError(t, run("testdata/user.yaml", out, md, js))
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	s := string(b)
+	assert.Contains(t, s, "// Code generated by errgen from user.yaml. DO NOT EDIT.")
+	assert.Contains(t, s, "package user\n")
+	assert.Contains(t, s, "import (\n\t\"google.golang.org/grpc/codes\"\n\n\t\"cxqi/common/errcode\"\n)")
+	assert.Contains(t, s, `var userNamespace = errcode.NewNamespace("user", 20000, 20999)`)
+	assert.Contains(t, s, `ErrNotFound = userNamespace.MustAdd("NotFound", errcode.NewGRPCErr(20001, "User not found", codes.NotFound))`)
+	assert.Contains(t, s, `ErrDisabled = userNamespace.Register("Disabled", 20002, "User is disabled | contact support", 403)`)
+	assert.Contains(t, s, `ErrLocked = userNamespace.MustAdd("Locked", errcode.NewGRPCErr(20003, "User is locked", codes.FailedPrecondition, 423))`)
+	assert.Contains(t, s, `errcode.RegisterMessages("zh-CN", map[uint32]string{`)
+	assert.Contains(t, s, `20001: "用户不存在",`)
+	assert.NotContains(t, s, "20002: ")
+
+	b, err = os.ReadFile(md)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "| 20001 | NotFound | 404 Not Found | NotFound | User not found | 用户不存在 |")
+	assert.Contains(t, string(b), "| 20002 | Disabled | 403 Forbidden | PermissionDenied | User is disabled \\| contact support |  |")
+
+	b, err = os.ReadFile(js)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"grpc_code": "NotFound"`)
+}
+
+func TestLoadCatalog_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `package = "x"
+unknown = 1`,
+		"empty namespace": `package = "x"
+[[errors]]
+name = "A"
+code = 1
+messages = { en = "a" }`,
+		"out of range": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 11
+messages = { en = "a" }`,
+		"duplicate code": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 1
+messages = { en = "a" }
+[[errors]]
+name = "B"
+code = 1
+messages = { en = "b" }`,
+		"duplicate var": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 1
+aliases = ["ErrB"]
+messages = { en = "a" }
+[[errors]]
+name = "B"
+code = 2
+messages = { en = "b" }`,
+		"missing default message": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 1
+messages = { zh-CN = "a" }`,
+		"illegal http status": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 1
+http = 999
+messages = { en = "a" }`,
+		"illegal grpc code": `package = "x"
+namespace = { name = "x", min = 1, max = 10 }
+[[errors]]
+name = "A"
+code = 1
+grpc = "Missing"
+messages = { en = "a" }`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range cases {
+		filename := filepath.Join(dir, "errors.toml")
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+		_, err := loadCatalog(filename)
+		assert.Error(t, err, name)
+	}
+
+	_, err := loadCatalog("testdata/user.json")
+	assert.Error(t, err)
+}
+
+func TestMapping_MatchesErrcode(t *testing.T) {
+	assert.Equal(t, errcode.DefaultLocale, defaultLocale)
+	assert.Equal(t, errcode.StatusClientClosedRequest, statusClientClosedRequest)
+
+	for httpCode := 100; httpCode < 600; httpCode++ {
+		assert.Equal(t, errcode.HTTPToGRPC(httpCode), httpToGRPC(httpCode), "http %d", httpCode)
+		for _, code := range []uint32{errcode.CodeOK, 20001} {
+			assert.Equal(t, errcode.NewErr(code, "", httpCode).GRPCCode(), derivedGRPCCode(code, httpCode),
+				"code %d http %d", code, httpCode)
+		}
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		assert.Equal(t, errcode.GRPCToHTTP(c), grpcToHTTP(c), "grpc %s", c)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	// errcodePath import path of the errcode package
+	errcodePath = "cxqi/common/errcode"
+
+	// codesPath import path of the gRPC codes package
+	codesPath = "google.golang.org/grpc/codes"
+)
+
+// goTemplate template of the generated Go code
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`// Code generated by errgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+{{if .Imports}}
+import (
+{{.Imports}})
+{{end}}
+// {{.Namespace.Var}} namespace of the business errors
+var {{.Namespace.Var}} = {{.Qual}}NewNamespace({{quote .Namespace.Name}}, {{.Namespace.Min}}, {{.Namespace.Max}})
+
+// business errors
+var (
+{{- range .Errors}}
+	// {{.Var}} {{index .Messages $.DefaultLocale}}
+	{{.Var}} = {{.Expr}}
+{{- end}}
+)
+{{- if .Aliases}}
+
+// deprecated aliases of business errors
+var (
+{{- range .Aliases}}
+	// {{.Alias}} {{.Message}}
+	//
+	// Deprecated: use {{.Var}} instead.
+	{{.Alias}} = {{.Var}}
+{{- end}}
+)
+{{- end}}
+{{- if .Catalogs}}
+
+func init() {
+{{- range .Catalogs}}
+	{{$.Qual}}RegisterMessages({{quote .Locale}}, map[uint32]string{
+	{{- range .Messages}}
+		{{.Code}}: {{quote .Message}},
+	{{- end}}
+	})
+{{- end}}
+}
+{{- end}}
+`))
+
+// alias deprecated alias of a business error
+type alias struct {
+	Alias, Var, Message string
+}
+
+// localeMessage localized message of a code
+type localeMessage struct {
+	Code    uint32
+	Message string
+}
+
+// localeCatalog localized messages of a locale
+type localeCatalog struct {
+	Locale   string
+	Messages []localeMessage
+}
+
+// errorDef business error definition with its registration expression
+type errorDef struct {
+	Error
+	Expr string
+}
+
+// generateGo generates the Go sentinel variables and registry entries of the catalog
+func generateGo(c *Catalog, source string) ([]byte, error) {
+	data := struct {
+		*Catalog
+		Source        string
+		Qual          string
+		Imports       string
+		DefaultLocale string
+		Errors        []errorDef
+		Aliases       []alias
+		Catalogs      []localeCatalog
+	}{Catalog: c, Source: source, DefaultLocale: defaultLocale}
+
+	if c.Package != "errcode" {
+		data.Qual = "errcode."
+	}
+	grpcDeclared := false
+	for _, e := range c.Errors {
+		data.Errors = append(data.Errors, errorDef{Error: e, Expr: registerExpr(c, e, data.Qual)})
+		for _, a := range e.Aliases {
+			data.Aliases = append(data.Aliases, alias{Alias: a, Var: e.Var, Message: e.Messages[defaultLocale]})
+		}
+		grpcDeclared = grpcDeclared || e.grpcDeclared
+	}
+	var imports []string
+	if grpcDeclared {
+		imports = append(imports, strconv.Quote(codesPath))
+	}
+	if data.Qual != "" {
+		imports = append(imports, strconv.Quote(errcodePath))
+	}
+	for _, imp := range imports {
+		if data.Imports != "" {
+			data.Imports += "\n"
+		}
+		data.Imports += "\t" + imp + "\n"
+	}
+	for _, locale := range c.locales()[1:] {
+		lc := localeCatalog{Locale: locale}
+		for _, e := range c.sorted() {
+			if msg, ok := e.Messages[locale]; ok {
+				lc.Messages = append(lc.Messages, localeMessage{Code: e.Code, Message: msg})
+			}
+		}
+		data.Catalogs = append(data.Catalogs, lc)
+	}
+
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+
+	return b, nil
+}
+
+// registerExpr registration expression of the business error,
+// the gRPC code and the HTTP status code are only emitted when they differ from the derived ones
+func registerExpr(c *Catalog, e Error, qual string) string {
+	msg := strconv.Quote(e.Messages[defaultLocale])
+	if !e.grpcDeclared {
+		expr := fmt.Sprintf("%s.Register(%q, %d, %s", c.Namespace.Var, e.Name, e.Code, msg)
+		if e.HTTP != http.StatusOK {
+			expr += fmt.Sprintf(", %d", e.HTTP)
+		}
+		return expr + ")"
+	}
+
+	expr := fmt.Sprintf("%sNewGRPCErr(%d, %s, codes.%s", qual, e.Code, msg, e.GRPC)
+	if e.HTTP != grpcToHTTP(grpcCodes[e.GRPC]) {
+		expr += fmt.Sprintf(", %d", e.HTTP)
+	}
+	return fmt.Sprintf("%s.MustAdd(%q, %s))", c.Namespace.Var, e.Name, expr)
+}
+
+// generateMarkdown generates the markdown error reference of the catalog
+func generateMarkdown(c *Catalog, source string) []byte {
+	locales := c.locales()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!-- Code generated by errgen from %s. DO NOT EDIT. -->\n\n", source)
+	fmt.Fprintf(&buf, "# %s error codes\n\n", c.Namespace.Name)
+	fmt.Fprintf(&buf, "Reserved code range: %d - %d\n\n", c.Namespace.Min, c.Namespace.Max)
+
+	buf.WriteString("| Code | Name | HTTP | gRPC |")
+	for _, locale := range locales {
+		buf.WriteString(" " + locale + " |")
+	}
+	buf.WriteString("\n|---|---|---|---|" + strings.Repeat("---|", len(locales)) + "\n")
+
+	for _, e := range c.sorted() {
+		fmt.Fprintf(&buf, "| %d | %s | %d %s | %s |", e.Code, e.Name, e.HTTP, http.StatusText(e.HTTP), e.GRPC)
+		for _, locale := range locales {
+			buf.WriteString(" " + escapeMarkdown(e.Messages[locale]) + " |")
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes()
+}
+
+// generateJSON generates the JSON error reference of the catalog
+func generateJSON(c *Catalog) ([]byte, error) {
+	ref := struct {
+		Namespace Namespace `json:"namespace"`
+		Locales   []string  `json:"locales"`
+		Errors    []Error   `json:"errors"`
+	}{Namespace: c.Namespace, Locales: c.locales(), Errors: c.sorted()}
+
+	b, err := json.MarshalIndent(ref, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// escapeMarkdown escapes the table cell content
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
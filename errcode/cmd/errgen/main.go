@@ -0,0 +1,66 @@
+// Command errgen generates the business error sentinel variables, registry entries,
+// localized message catalogs and a markdown/JSON error reference from a TOML or YAML catalog.
+//
+// Usage:
+//
+//	errgen -in errors.toml -out errors_gen.go [-md errors.md] [-json errors.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	in := flag.String("in", "", "catalog file, .toml, .yaml or .yml")
+	out := flag.String("out", "", "generated Go file")
+	md := flag.String("md", "", "generated markdown error reference, optional")
+	js := flag.String("json", "", "generated JSON error reference, optional")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out, *md, *js); err != nil {
+		fmt.Fprintln(os.Stderr, "errgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the files from the catalog
+func run(in, out, md, js string) error {
+	c, err := loadCatalog(in)
+	if err != nil {
+		return err
+	}
+	source := filepath.Base(in)
+
+	b, err := generateGo(c, source)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(out, b, 0644); err != nil {
+		return err
+	}
+
+	if md != "" {
+		if err = os.WriteFile(md, generateMarkdown(c, source), 0644); err != nil {
+			return err
+		}
+	}
+
+	if js != "" {
+		if b, err = generateJSON(c); err != nil {
+			return err
+		}
+		if err = os.WriteFile(js, b, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
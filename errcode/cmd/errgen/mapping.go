@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// The mapping below mirrors errcode/status.go, it is copied rather than imported
+// so that errgen still builds when the generated code of the errcode package is missing or broken.
+
+const (
+	// defaultLocale locale of the messages the business errors are created with, errcode.DefaultLocale
+	defaultLocale = "en"
+
+	// codeOK business status code of successful requests, errcode.CodeOK
+	codeOK = http.StatusOK
+
+	// statusClientClosedRequest non-standard HTTP status code of requests canceled by clients,
+	// errcode.StatusClientClosedRequest
+	statusClientClosedRequest = 499
+)
+
+// derivedGRPCCode gRPC canonical code derived from the business status code and HTTP status code, errcode.Err.GRPCCode
+func derivedGRPCCode(code uint32, httpCode int) codes.Code {
+	if code == codeOK {
+		return codes.OK
+	}
+	if c := httpToGRPC(httpCode); c != codes.OK {
+		return c
+	}
+
+	return codes.FailedPrecondition
+}
+
+// httpToGRPC maps the HTTP status code to the gRPC canonical code, errcode.HTTPToGRPC
+func httpToGRPC(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case statusClientClosedRequest:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+
+	switch {
+	case httpCode >= http.StatusOK && httpCode < http.StatusMultipleChoices:
+		return codes.OK
+	case httpCode >= http.StatusBadRequest && httpCode < http.StatusInternalServerError:
+		return codes.FailedPrecondition
+	case httpCode >= http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// grpcToHTTP maps the gRPC canonical code to the HTTP status code, errcode.GRPCToHTTP
+func grpcToHTTP(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return statusClientClosedRequest
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,159 @@
+package errcode
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Details structured error details returned to clients
+type Details struct {
+	// FieldViolations request fields that failed validation
+	FieldViolations []FieldViolation `json:"field_violations,omitempty"`
+
+	// RetryDelayMs how long clients should wait before retrying, in milliseconds
+	RetryDelayMs int64 `json:"retry_delay_ms,omitempty"`
+
+	// Metadata arbitrary key-value pairs
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// FieldViolation a single request field that failed validation
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// RetryDelay how long clients should wait before retrying
+func (d *Details) RetryDelay() time.Duration {
+	return time.Duration(d.RetryDelayMs) * time.Millisecond
+}
+
+// empty reports whether there are no details
+func (d *Details) empty() bool {
+	return len(d.FieldViolations) == 0 && d.RetryDelayMs == 0 && len(d.Metadata) == 0
+}
+
+// clone deep copies the details, a nil receiver returns empty details
+func (d *Details) clone() *Details {
+	c := &Details{}
+	if d == nil {
+		return c
+	}
+
+	c.FieldViolations = append([]FieldViolation(nil), d.FieldViolations...)
+	c.RetryDelayMs = d.RetryDelayMs
+	if d.Metadata != nil {
+		c.Metadata = make(map[string]string, len(d.Metadata))
+		for k, v := range d.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+
+	return c
+}
+
+// Details returns the structured error details, nil if there are none
+func (e *Err) Details() *Details {
+	return e.details
+}
+
+// WithFieldViolation returns a copy of the business error with a field violation added
+func (e *Err) WithFieldViolation(field, description string) *Err {
+	return e.withDetails(func(d *Details) {
+		d.FieldViolations = append(d.FieldViolations, FieldViolation{Field: field, Description: description})
+	})
+}
+
+// WithRetryDelay returns a copy of the business error telling clients how long to wait before retrying
+func (e *Err) WithRetryDelay(delay time.Duration) *Err {
+	return e.withDetails(func(d *Details) {
+		d.RetryDelayMs = delay.Milliseconds()
+	})
+}
+
+// WithMetadata returns a copy of the business error with the key-value pairs added to the metadata
+func (e *Err) WithMetadata(md map[string]string) *Err {
+	return e.withDetails(func(d *Details) {
+		if d.Metadata == nil {
+			d.Metadata = make(map[string]string, len(md))
+		}
+		for k, v := range md {
+			d.Metadata[k] = v
+		}
+	})
+}
+
+// withDetails returns a copy of the business error with modified details, the original is left untouched
+func (e *Err) withDetails(f func(d *Details)) *Err {
+	err := *e
+	d := e.details.clone()
+	f(d)
+	if d.empty() {
+		d = nil
+	}
+	err.details = d
+
+	return &err
+}
+
+// proto encodes the field violations and retry info as google.rpc error details,
+// the metadata is carried by the ErrorInfo detail built in GRPCStatus
+func (d *Details) proto() []protoadapt.MessageV1 {
+	var msgs []protoadapt.MessageV1
+	if len(d.FieldViolations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range d.FieldViolations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		msgs = append(msgs, br)
+	}
+	if d.RetryDelayMs > 0 {
+		msgs = append(msgs, &errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryDelay())})
+	}
+
+	return msgs
+}
+
+// detailsFromStatus decodes google.rpc error details of the gRPC status, nil if there are none
+func detailsFromStatus(s *status.Status) *Details {
+	d := &Details{}
+	for _, detail := range s.Details() {
+		switch v := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, fv := range v.GetFieldViolations() {
+				d.FieldViolations = append(d.FieldViolations, FieldViolation{
+					Field:       fv.GetField(),
+					Description: fv.GetDescription(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			d.RetryDelayMs = v.GetRetryDelay().AsDuration().Milliseconds()
+		case *errdetails.ErrorInfo:
+			if v.GetDomain() == ItemErrorDomain {
+				continue
+			}
+			for k, val := range v.GetMetadata() {
+				if v.GetDomain() == ErrorDomain && k == MetadataHTTPCode {
+					continue
+				}
+				if d.Metadata == nil {
+					d.Metadata = make(map[string]string, len(v.GetMetadata()))
+				}
+				d.Metadata[k] = val
+			}
+		}
+	}
+
+	if d.empty() {
+		return nil
+	}
+
+	return d
+}
@@ -0,0 +1,54 @@
+package errcode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/status"
+)
+
+func TestErr_WithDetails(t *testing.T) {
+	assert.Nil(t, ErrInvalidParams.Details())
+
+	err := ErrInvalidParams.
+		WithFieldViolation("name", "required").
+		WithFieldViolation("age", "must be positive").
+		WithRetryDelay(1500 * time.Millisecond).
+		WithMetadata(map[string]string{"region": "eu"})
+
+	assert.Nil(t, ErrInvalidParams.Details())
+	assert.True(t, err.Is(ErrInvalidParams))
+	assert.Equal(t, &Details{
+		FieldViolations: []FieldViolation{{Field: "name", Description: "required"}, {Field: "age", Description: "must be positive"}},
+		RetryDelayMs:    1500,
+		Metadata:        map[string]string{"region": "eu"},
+	}, err.Details())
+	assert.Equal(t, 1500*time.Millisecond, err.Details().RetryDelay())
+
+	// copies do not affect each other
+	other := err.WithMetadata(map[string]string{"zone": "a"})
+	assert.Equal(t, map[string]string{"region": "eu"}, err.Details().Metadata)
+	assert.Equal(t, map[string]string{"region": "eu", "zone": "a"}, other.Details().Metadata)
+}
+
+func TestErr_GRPCStatusDetails(t *testing.T) {
+	err := ErrInvalidParams.
+		WithFieldViolation("name", "required").
+		WithRetryDelay(time.Second).
+		WithMetadata(map[string]string{"region": "eu"})
+	s := err.GRPCStatus()
+	assert.Len(t, s.Details(), 3)
+
+	// simulate transport across processes
+	s = status.FromProto(s.Proto())
+	e := ParseErr(s.Err())
+	assert.Equal(t, ErrInvalidParams.Code(), e.Code())
+	assert.Equal(t, ErrInvalidParams.HTTPCode(), e.HTTPCode())
+	assert.Equal(t, err.Details(), e.Details())
+
+	e = ParseErr(NewCustomErr("name is required").WithFieldViolation("name", "required").GRPCStatus().Err())
+	assert.Equal(t, uint32(CodeCustom), e.Code())
+	assert.Equal(t, "name is required", e.Error())
+	assert.Equal(t, []FieldViolation{{Field: "name", Description: "required"}}, e.Details().FieldViolations)
+}
@@ -2,8 +2,13 @@ package errcode
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"runtime"
+	"strconv"
 
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -20,6 +25,9 @@ const (
 
 	//MsgCustom custom error message
 	MsgCustom = "Custom error"
+
+	// maxStackDepth maximum depth of the captured call stack
+	maxStackDepth = 32
 )
 
 //Err business error structure
@@ -27,6 +35,10 @@ type Err struct {
 	code     uint32
 	httpCode int
 	msg      string
+	grpcCode codes.Code
+	details  *Details
+	cause    error
+	stack    []uintptr
 }
 
 //Code business status code
@@ -39,58 +51,90 @@ func (e *Err) HTTPCode() int {
 	return e.httpCode
 }
 
-//Error message
+//Error message, the cause is not included so that it is safe to return to clients
 func (e *Err) Error() string {
 	return e.msg
 }
 
-//business error
-var (
-	NoErr = NewErr(CodeOK, MsgOK)
-
-	ErrCustom     = NewErr(CodeCustom, MsgCustom)
-	ErrUnexpected = NewErr(7777, "Network error, please try again later", http.StatusInternalServerError)
-
-	ErrTokenNotValidYet    = NewErr(9999, "Token illegal", http.StatusUnauthorized)
-	ErrInvalidUrl          = NewErr(10000, "URL is illegal")
-	ErrInvalidHeader       = NewErr(10001, "Invalid request header")
-	ErrInvalidParams       = NewErr(10002, "Parameter is illegal")
-	ErrTokenVerify         = NewErr(10003, "Token check error", http.StatusUnauthorized)
-	ErrTokenExpire         = NewErr(10004, "Expired token", http.StatusUnauthorized)
-	ErrUserLogin           = NewErr(10005, "User log out", http.StatusUnauthorized)
-	ErrUserPrivilegeChange = NewErr(10006, "Permission changed", http.StatusUnauthorized)
-	ErrLockNotAcquire      = NewErr(10007, "Lock not released")
-	ErrLockAcquire         = NewErr(10008, "Lock acquisition error")
-	ErrLockNotRelease      = NewErr(10009, "Lock is not released")
-	ErrLockRelease         = NewErr(10010, "Lock released err")
-	ErrTWitterAddress      = NewErr(10011, "Twitter address illeage")
-	ErrDiscordAddress      = NewErr(10012, "Discord address illeage")
-	ErrAddress             = NewErr(10013, "Address illeage")
-)
+// WithCause returns a copy of the business error wrapping cause, capturing the call stack
+func (e *Err) WithCause(cause error) *Err {
+	err := *e
+	err.cause = cause
+	err.stack = callers()
+	return &err
+}
+
+// Unwrap returns the wrapped cause
+func (e *Err) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is a business error with the same code,
+// so that errors.Is matches copies and wrapped errors against sentinels like ErrTokenExpire
+func (e *Err) Is(target error) bool {
+	t, ok := target.(*Err)
+	return ok && t != nil && t.code == e.code
+}
+
+// Format formats the business error, %+v prints the code, the cause chain and the call stack
+func (e *Err) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, strconv.FormatUint(uint64(e.code), 10)+" "+e.msg)
+			if e.cause != nil {
+				_, _ = fmt.Fprintf(s, "\ncaused by: %+v", e.cause)
+			}
+			e.formatStack(s)
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.msg)
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.msg)
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(*errcode.Err=%s)", verb, e.msg)
+	}
+}
+
+// formatStack writes the captured call stack
+func (e *Err) formatStack(w io.Writer) {
+	if len(e.stack) == 0 {
+		return
+	}
+
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		f, more := frames.Next()
+		_, _ = fmt.Fprintf(w, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			return
+		}
+	}
+}
+
+// Wrap wraps err with the business error e, capturing the call stack, returns nil if err is nil
+func Wrap(err error, e *Err) error {
+	if err == nil {
+		return nil
+	}
 
-var codeToErr = map[uint32]*Err{
-	200: NoErr,
-
-	7000: ErrCustom,
-	7777: ErrUnexpected,
-
-	9999:  ErrTokenNotValidYet,
-	10000: ErrInvalidUrl,
-	10001: ErrInvalidHeader,
-	10002: ErrInvalidParams,
-	10003: ErrTokenVerify,
-	10004: ErrTokenExpire,
-	10005: ErrUserLogin,
-	10006: ErrUserPrivilegeChange,
-	10007: ErrLockNotAcquire,
-	10008: ErrLockAcquire,
-	10009: ErrLockNotRelease,
-	10010: ErrLockRelease,
-	10011: ErrTWitterAddress,
-	10012: ErrDiscordAddress,
-	10013: ErrAddress,
+	w := *e
+	w.cause = err
+	w.stack = callers()
+	return &w
 }
 
+// callers captures the call stack of the caller of the exported function
+func callers() []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return pcs[:n]
+}
+
+//go:generate go run ./cmd/errgen -in errors.toml -out errors_gen.go -md errors.md -json errors.json
+
 //NewErr creates a new business error
 func NewErr(code uint32, msg string, httpCode ...int) *Err {
 	hc := http.StatusOK
@@ -100,17 +144,30 @@ func NewErr(code uint32, msg string, httpCode ...int) *Err {
 	return &Err{code: code, httpCode: hc, msg: msg}
 }
 
-func GetCodeToErr() map[uint32]*Err {
-	return codeToErr
+// NewGRPCErr creates a new business error declaring its gRPC canonical code,
+// the HTTP status code is derived from the gRPC code if not specified
+func NewGRPCErr(code uint32, msg string, grpcCode codes.Code, httpCode ...int) *Err {
+	hc := GRPCToHTTP(grpcCode)
+	if len(httpCode) != 0 {
+		hc = httpCode[0]
+	}
+	return &Err{code: code, httpCode: hc, msg: msg, grpcCode: grpcCode}
 }
 
-func SetCodeToErr(code uint32, err *Err) error {
-	if _, ok := codeToErr[code]; ok {
-		return errors.New("has exist")
+// GetCodeToErr returns a snapshot of the registered business errors by code
+func GetCodeToErr() map[uint32]*Err {
+	m := make(map[uint32]*Err)
+	for _, e := range std.Entries() {
+		m[e.Err.code] = e.Err
 	}
 
-	codeToErr[code] = err
-	return nil
+	return m
+}
+
+// SetCodeToErr registers the business error outside any namespace, returns an error if the code is taken.
+// Prefer Namespace.Register which also checks the reserved code range
+func SetCodeToErr(code uint32, err *Err) error {
+	return std.add(code, Entry{Err: err})
 }
 
 // NewCustomErr creates a new custom error
@@ -118,38 +175,59 @@ func NewCustomErr(msg string, httpCode ...int) *Err {
 	return NewErr(CodeCustom, msg, httpCode...)
 }
 
-// IsErr judges whether it is a business error
+// IsErr judges whether it is a business error or wraps a business error
 func IsErr(err error) bool {
 	if err == nil {
 		return true
 	}
 
-	_, ok := err.(*Err)
-	return ok
+	var e *Err
+	return errors.As(err, &e)
 }
 
-// ParseErr parsing business errors
+// ParseErr parsing business errors, the outermost business error in the chain is returned,
+// the overall business error is returned if an aggregate error comes first,
+// gRPC status errors are reconstructed from their details
 func ParseErr(err error) *Err {
 	if err == nil {
 		return NoErr
 	}
 
-	if e, ok := err.(*Err); ok {
+	e, m := AsErr(err)
+	if e != nil {
 		return e
 	}
+	if m != nil {
+		return m.Err()
+	}
 
 	s, _ := status.FromError(err)
-	c := uint32(s.Code())
-	if c == CodeCustom {
-		return NewCustomErr(s.Message())
+	if e, ok := fromStatus(s); ok {
+		return e
+	}
+
+	// canonical gRPC codes of other services are unexpected errors with the mapped HTTP status code
+	if s.Code() > codes.Unknown && s.Code() <= codes.Unauthenticated {
+		e = &Err{code: ErrUnexpected.code, httpCode: GRPCToHTTP(s.Code()), msg: ErrUnexpected.msg, grpcCode: s.Code()}
+	} else if c := uint32(s.Code()); c == CodeCustom {
+		// status errors without the business error detail carry the business status code as the gRPC code
+		e = NewCustomErr(s.Message())
+	} else {
+		e = ParseCode(c)
+	}
+
+	if d := detailsFromStatus(s); d != nil {
+		de := *e
+		de.details = d
+		return &de
 	}
 
-	return ParseCode(c)
+	return e
 }
 
 // ParseCode parses the business error corresponding to the business status code
 func ParseCode(code uint32) *Err {
-	if e, ok := codeToErr[code]; ok {
+	if e, ok := std.ByCode(code); ok {
 		return e
 	}
 
@@ -1 +1,72 @@
 package errcode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErr_WithCause(t *testing.T) {
+	cause := io.ErrUnexpectedEOF
+	err := ErrTokenExpire.WithCause(cause)
+
+	assert.NotSame(t, ErrTokenExpire, err)
+	assert.Nil(t, ErrTokenExpire.Unwrap())
+	assert.Equal(t, ErrTokenExpire.Code(), err.Code())
+	assert.Equal(t, ErrTokenExpire.HTTPCode(), err.HTTPCode())
+	assert.Equal(t, ErrTokenExpire.Error(), err.Error())
+	assert.Equal(t, cause, err.Unwrap())
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, errors.Is(err, ErrTokenExpire))
+	assert.False(t, errors.Is(err, ErrTokenVerify))
+}
+
+func TestWrap(t *testing.T) {
+	assert.Nil(t, Wrap(nil, ErrInvalidParams))
+
+	cause := errors.New("bad json")
+	err := fmt.Errorf("handle: %w", Wrap(cause, ErrInvalidParams))
+
+	assert.True(t, errors.Is(err, ErrInvalidParams))
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, IsErr(err))
+
+	var e *Err
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrInvalidParams.Code(), e.Code())
+
+	e = ParseErr(err)
+	assert.Equal(t, ErrInvalidParams.Code(), e.Code())
+	assert.Equal(t, ErrInvalidParams.Error(), e.Error())
+	assert.Equal(t, cause, e.Unwrap())
+
+	assert.True(t, errors.Is(pkgErrors.WithStack(ErrTokenExpire), ErrTokenExpire))
+	assert.Same(t, ErrTokenExpire, ParseErr(pkgErrors.Wrap(ErrTokenExpire, "parse token")))
+
+	var nilErr *Err
+	assert.False(t, errors.Is(ErrTokenExpire, nilErr))
+}
+
+func TestErr_Format(t *testing.T) {
+	cause := pkgErrors.New("connection refused")
+	err := Wrap(cause, ErrUnexpected)
+
+	assert.Equal(t, ErrUnexpected.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, ErrUnexpected.Error(), fmt.Sprintf("%s", err))
+	assert.Equal(t, fmt.Sprintf("%q", ErrUnexpected.Error()), fmt.Sprintf("%q", err))
+
+	s := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(s, "7777 "+ErrUnexpected.Error()+"\ncaused by: connection refused\n"), s)
+	assert.Contains(t, s, "errcode.TestErr_Format")
+	assert.Equal(t, 2, strings.Count(s, "errcode.TestErr_Format\n"), s)
+
+	s = fmt.Sprintf("%+v", ErrTokenExpire)
+	assert.Equal(t, "10004 "+ErrTokenExpire.Error(), s)
+
+	assert.Equal(t, "%!d(*errcode.Err="+ErrTokenExpire.Error()+")", fmt.Sprintf("%d", ErrTokenExpire))
+}
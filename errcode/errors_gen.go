@@ -0,0 +1,81 @@
+// Code generated by errgen from errors.toml. DO NOT EDIT.
+
+package errcode
+
+import (
+	"google.golang.org/grpc/codes"
+)
+
+// common namespace of the business errors
+var common = NewNamespace("common", 0, 19999)
+
+// business errors
+var (
+	// NoErr Successful
+	NoErr = common.Register("OK", 200, "Successful")
+	// ErrCustom Custom error
+	ErrCustom = common.Register("Custom", 7000, "Custom error")
+	// ErrUnexpected Network error, please try again later
+	ErrUnexpected = common.Register("Unexpected", 7777, "Network error, please try again later", 500)
+	// ErrTokenNotValidYet Token illegal
+	ErrTokenNotValidYet = common.Register("TokenNotValidYet", 9999, "Token illegal", 401)
+	// ErrInvalidUrl URL is illegal
+	ErrInvalidUrl = common.MustAdd("InvalidUrl", NewGRPCErr(10000, "URL is illegal", codes.InvalidArgument, 200))
+	// ErrInvalidHeader Invalid request header
+	ErrInvalidHeader = common.MustAdd("InvalidHeader", NewGRPCErr(10001, "Invalid request header", codes.InvalidArgument, 200))
+	// ErrInvalidParams Parameter is illegal
+	ErrInvalidParams = common.MustAdd("InvalidParams", NewGRPCErr(10002, "Parameter is illegal", codes.InvalidArgument, 200))
+	// ErrTokenVerify Token check error
+	ErrTokenVerify = common.Register("TokenVerify", 10003, "Token check error", 401)
+	// ErrTokenExpire Expired token
+	ErrTokenExpire = common.Register("TokenExpire", 10004, "Expired token", 401)
+	// ErrUserLogin User log out
+	ErrUserLogin = common.Register("UserLogin", 10005, "User log out", 401)
+	// ErrUserPrivilegeChange Permission changed
+	ErrUserPrivilegeChange = common.Register("UserPrivilegeChange", 10006, "Permission changed", 401)
+	// ErrLockNotAcquire Lock not released
+	ErrLockNotAcquire = common.MustAdd("LockNotAcquire", NewGRPCErr(10007, "Lock not released", codes.Aborted, 200))
+	// ErrLockAcquire Lock acquisition error
+	ErrLockAcquire = common.MustAdd("LockAcquire", NewGRPCErr(10008, "Lock acquisition error", codes.Aborted, 200))
+	// ErrLockNotRelease Lock is not released
+	ErrLockNotRelease = common.MustAdd("LockNotRelease", NewGRPCErr(10009, "Lock is not released", codes.Aborted, 200))
+	// ErrLockRelease Lock released err
+	ErrLockRelease = common.MustAdd("LockRelease", NewGRPCErr(10010, "Lock released err", codes.Aborted, 200))
+	// ErrTwitterAddress Twitter address illeage
+	ErrTwitterAddress = common.MustAdd("TwitterAddress", NewGRPCErr(10011, "Twitter address illeage", codes.InvalidArgument, 200))
+	// ErrDiscordAddress Discord address illeage
+	ErrDiscordAddress = common.MustAdd("DiscordAddress", NewGRPCErr(10012, "Discord address illeage", codes.InvalidArgument, 200))
+	// ErrAddress Address illeage
+	ErrAddress = common.MustAdd("Address", NewGRPCErr(10013, "Address illeage", codes.InvalidArgument, 200))
+)
+
+// deprecated aliases of business errors
+var (
+	// ErrTWitterAddress Twitter address illeage
+	//
+	// Deprecated: use ErrTwitterAddress instead.
+	ErrTWitterAddress = ErrTwitterAddress
+)
+
+func init() {
+	RegisterMessages("zh-CN", map[uint32]string{
+		200:   "成功",
+		7000:  "自定义错误",
+		7777:  "网络错误，请稍后重试",
+		9999:  "令牌非法",
+		10000: "URL非法",
+		10001: "请求头无效",
+		10002: "参数非法",
+		10003: "令牌校验错误",
+		10004: "令牌已过期",
+		10005: "用户已登出",
+		10006: "权限已变更",
+		10007: "锁未释放",
+		10008: "获取锁错误",
+		10009: "锁未释放",
+		10010: "释放锁错误",
+		10011: "Twitter地址非法",
+		10012: "Discord地址非法",
+		10013: "地址非法",
+	})
+}
@@ -0,0 +1,173 @@
+package errcode
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// ErrorDomain domain of the ErrorInfo detail carrying the business error,
+	// its reason is the business status code
+	ErrorDomain = "errcode"
+
+	// MetadataHTTPCode ErrorInfo metadata key carrying the HTTP status code
+	MetadataHTTPCode = "http_code"
+)
+
+// GRPCStatus converts the business error to a gRPC status with its canonical code,
+// the business status code, HTTP status code and details are encoded as google.rpc error details
+func (e *Err) GRPCStatus() *status.Status {
+	if e.code == CodeOK {
+		return status.New(codes.OK, e.msg)
+	}
+
+	s := status.New(e.GRPCCode(), e.msg)
+	info := &errdetails.ErrorInfo{
+		Reason:   strconv.FormatUint(uint64(e.code), 10),
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{MetadataHTTPCode: strconv.Itoa(e.httpCode)},
+	}
+	if e.details != nil {
+		for k, v := range e.details.Metadata {
+			if k != MetadataHTTPCode {
+				info.Metadata[k] = v
+			}
+		}
+	}
+
+	var msgs []protoadapt.MessageV1
+	if e.details != nil {
+		msgs = e.details.proto()
+	}
+	ds, err := s.WithDetails(append(msgs, info)...)
+	if err != nil {
+		return s
+	}
+
+	return ds
+}
+
+// fromStatus reconstructs the business error from the ErrorInfo detail of the gRPC status,
+// registered errors are returned as is when nothing differs
+func fromStatus(s *status.Status) (*Err, bool) {
+	var info *errdetails.ErrorInfo
+	for _, detail := range s.Details() {
+		if v, ok := detail.(*errdetails.ErrorInfo); ok && v.GetDomain() == ErrorDomain {
+			info = v
+			break
+		}
+	}
+	if info == nil {
+		return nil, false
+	}
+
+	code, err := strconv.ParseUint(info.GetReason(), 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	httpCode, err := strconv.Atoi(info.GetMetadata()[MetadataHTTPCode])
+	if err != nil {
+		httpCode = http.StatusOK
+	}
+
+	e := &Err{code: uint32(code), httpCode: httpCode, msg: s.Message(), details: detailsFromStatus(s)}
+	if s.Code() != e.GRPCCode() {
+		e.grpcCode = s.Code()
+	}
+	if re, ok := std.ByCode(e.code); ok && re.msg == e.msg && re.httpCode == e.httpCode &&
+		re.GRPCCode() == e.GRPCCode() && e.details == nil {
+		return re, true
+	}
+
+	return e, true
+}
+
+// ToGRPCErr converts the aggregate error or business error in the chain of err to a gRPC status error,
+// other errors are returned unchanged
+func ToGRPCErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e, m := AsErr(err)
+	if e != nil {
+		return e.GRPCStatus().Err()
+	}
+	if m != nil {
+		return m.GRPCStatus().Err()
+	}
+
+	return err
+}
+
+// FromGRPCErr reconstructs the business error or the aggregate error carried by the gRPC status error,
+// other errors are returned unchanged
+func FromGRPCErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if s, ok := status.FromError(err); ok {
+		if m, ok := multiErrFromStatus(s); ok {
+			return m
+		}
+		if e, ok := fromStatus(s); ok {
+			return e
+		}
+	}
+
+	return err
+}
+
+// UnaryServerInterceptor converts business errors returned by unary handlers to gRPC status errors,
+// messages are localized with the locale resolved from the context
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, ToGRPCErr(Localize(ctx, err))
+	}
+}
+
+// StreamServerInterceptor converts business errors returned by stream handlers to gRPC status errors,
+// messages are localized with the locale resolved from the context
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return ToGRPCErr(Localize(ss.Context(), handler(srv, ss)))
+	}
+}
+
+// UnaryClientInterceptor reconstructs business errors from gRPC status errors returned by unary calls
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromGRPCErr(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor reconstructs business errors from gRPC status errors returned by stream calls
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromGRPCErr(err)
+		}
+
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+// clientStream client stream reconstructing business errors from received gRPC status errors
+type clientStream struct {
+	grpc.ClientStream
+}
+
+// RecvMsg receives a message
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return FromGRPCErr(s.ClientStream.RecvMsg(m))
+}
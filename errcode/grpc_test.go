@@ -0,0 +1,134 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestErr_GRPCStatus(t *testing.T) {
+	s := ErrTokenExpire.GRPCStatus()
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+	assert.Equal(t, ErrTokenExpire.Error(), s.Message())
+	assert.Same(t, ErrTokenExpire, ParseErr(s.Err()))
+
+	assert.Equal(t, codes.Internal, ErrUnexpected.GRPCStatus().Code())
+	assert.Equal(t, codes.InvalidArgument, ErrInvalidParams.GRPCStatus().Code())
+	assert.Equal(t, codes.Aborted, ErrLockNotAcquire.GRPCStatus().Code())
+	assert.Equal(t, codes.FailedPrecondition, ErrCustom.GRPCStatus().Code())
+	assert.Equal(t, codes.OK, NoErr.GRPCStatus().Code())
+	assert.Equal(t, codes.NotFound, NewErr(20001, "not found", http.StatusNotFound).GRPCStatus().Code())
+
+	// unregistered errors and modified messages are reconstructed exactly
+	e := ParseErr(NewErr(20001, "not found", http.StatusNotFound).GRPCStatus().Err())
+	assert.Equal(t, uint32(20001), e.Code())
+	assert.Equal(t, http.StatusNotFound, e.HTTPCode())
+	assert.Equal(t, "not found", e.Error())
+
+	e = ParseErr(NewCustomErr("name is required").GRPCStatus().Err())
+	assert.Equal(t, uint32(CodeCustom), e.Code())
+	assert.Equal(t, "name is required", e.Error())
+
+	// status errors without the business error detail keep the previous behavior
+	assert.Same(t, ErrInvalidParams, ParseErr(status.Error(codes.Code(ErrInvalidParams.Code()), "")))
+	assert.Same(t, ErrUnexpected, ParseErr(errors.New("connection refused")))
+
+	// canonical codes of other services keep their HTTP status
+	e = ParseErr(status.Error(codes.Unavailable, "connection refused"))
+	assert.True(t, e.Is(ErrUnexpected))
+	assert.Equal(t, ErrUnexpected.Error(), e.Error())
+	assert.Equal(t, http.StatusServiceUnavailable, e.HTTPCode())
+	assert.Equal(t, codes.Unavailable, e.GRPCCode())
+}
+
+func TestToGRPCErr(t *testing.T) {
+	assert.Nil(t, ToGRPCErr(nil))
+	assert.Nil(t, ToGRPCErr(NoErr))
+
+	other := errors.New("other")
+	assert.Equal(t, other, ToGRPCErr(other))
+
+	err := ToGRPCErr(pkgErrors.WithMessage(Wrap(other, ErrTokenExpire), "parse token"))
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+	assert.Equal(t, ErrTokenExpire.Error(), s.Message())
+
+	assert.Same(t, ErrTokenExpire, FromGRPCErr(err))
+	assert.Nil(t, FromGRPCErr(nil))
+	assert.Equal(t, other, FromGRPCErr(other))
+
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	assert.Equal(t, unavailable, FromGRPCErr(unavailable))
+}
+
+func TestInterceptors(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			switch method {
+			case "/test.Service/Expire":
+				return pkgErrors.Wrap(ErrTokenExpire.WithCause(errors.New("token is expired")), "parse token")
+			case "/test.Service/Details":
+				return ErrInvalidParams.WithFieldViolation("name", "required").WithMetadata(map[string]string{"region": "eu"})
+			default:
+				return status.Error(codes.Unimplemented, "unknown method")
+			}
+		}),
+	)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	err = conn.Invoke(ctx, "/test.Service/Expire", &emptypb.Empty{}, &emptypb.Empty{})
+	assert.Same(t, ErrTokenExpire, err)
+	assert.True(t, errors.Is(err, ErrTokenExpire))
+
+	err = conn.Invoke(ctx, "/test.Service/Details", &emptypb.Empty{}, &emptypb.Empty{})
+	var e *Err
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrInvalidParams.Code(), e.Code())
+	assert.Equal(t, ErrInvalidParams.Error(), e.Error())
+	assert.Equal(t, &Details{
+		FieldViolations: []FieldViolation{{Field: "name", Description: "required"}},
+		Metadata:        map[string]string{"region": "eu"},
+	}, e.Details())
+
+	err = conn.Invoke(ctx, "/test.Service/Other", &emptypb.Empty{}, &emptypb.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/test.Service/Expire")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&emptypb.Empty{}))
+	require.NoError(t, stream.CloseSend())
+	assert.Same(t, ErrTokenExpire, stream.RecvMsg(&emptypb.Empty{}))
+
+	resp, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", ErrTokenExpire
+	})
+	assert.Equal(t, "resp", resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,236 @@
+package errcode
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// DefaultLocale locale of the messages the business errors are created with
+	DefaultLocale = "en"
+
+	// LocaleZhCN simplified Chinese
+	LocaleZhCN = "zh-CN"
+
+	// HeaderAcceptLanguage HTTP header and gRPC metadata key carrying the preferred locales
+	HeaderAcceptLanguage = "Accept-Language"
+
+	// metadataGatewayAcceptLanguage Accept-Language forwarded by grpc-gateway
+	metadataGatewayAcceptLanguage = "grpcgateway-accept-language"
+)
+
+// RegisterMessages adds the localized messages by code to the catalog of the locale in the default registry
+func RegisterMessages(locale string, msgs map[uint32]string) {
+	std.RegisterMessages(locale, msgs)
+}
+
+// RegisterMessages adds the localized messages by code to the catalog of the locale
+func (r *Registry) RegisterMessages(locale string, msgs map[uint32]string) {
+	locale = normalizeLocale(locale)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	catalog, ok := r.messages[locale]
+	if !ok {
+		catalog = make(map[uint32]string, len(msgs))
+		r.messages[locale] = catalog
+	}
+	for code, msg := range msgs {
+		catalog[code] = msg
+	}
+}
+
+// Message looks up the localized message of the code
+func (r *Registry) Message(code uint32, locale string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	msg, ok := r.messages[normalizeLocale(locale)][code]
+	return msg, ok
+}
+
+// Locales returns the locales having a catalog, the default locale included
+func (r *Registry) Locales() []string {
+	r.mu.RLock()
+	locales := []string{DefaultLocale}
+	for locale := range r.messages {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// MatchLocale picks the supported locale best matching the Accept-Language value,
+// empty if none matches
+func (r *Registry) MatchLocale(acceptLanguage string) string {
+	locales := r.Locales()
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if locale := matchLocale(locales, tag); locale != "" {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// Localize returns a copy of the business error with the message of the locale,
+// errors whose message differs from the registered one, such as custom errors, are returned unchanged
+func (e *Err) Localize(locale string) *Err {
+	if locale == "" || normalizeLocale(locale) == DefaultLocale {
+		return e
+	}
+
+	if re, ok := std.ByCode(e.code); !ok || re.msg != e.msg {
+		return e
+	}
+
+	msg, ok := std.Message(e.code, locale)
+	if !ok || msg == e.msg {
+		return e
+	}
+
+	err := *e
+	err.msg = msg
+	return &err
+}
+
+// MatchLocale picks the locale of the default registry best matching the Accept-Language value
+func MatchLocale(acceptLanguage string) string {
+	return std.MatchLocale(acceptLanguage)
+}
+
+// localeKey context key of the locale
+type localeKey struct{}
+
+// WithLocale returns a context carrying the locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext resolves the locale from the context, falling back to the Accept-Language of the gRPC metadata
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{HeaderAcceptLanguage, metadataGatewayAcceptLanguage} {
+			if vs := md.Get(key); len(vs) > 0 {
+				if locale := MatchLocale(strings.Join(vs, ",")); locale != "" {
+					return locale
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// LocaleFromRequest resolves the locale from the request context, falling back to the Accept-Language header
+func LocaleFromRequest(r *http.Request) string {
+	if locale := LocaleFromContext(r.Context()); locale != "" {
+		return locale
+	}
+
+	return MatchLocale(r.Header.Get(HeaderAcceptLanguage))
+}
+
+// Localize localizes the aggregate error or business error in the chain of err with the locale resolved from the context,
+// the localized error replaces err, other errors are returned unchanged
+func Localize(ctx context.Context, err error) error {
+	e, m := AsErr(err)
+	if m != nil {
+		return m.Localize(LocaleFromContext(ctx))
+	}
+	if e == nil {
+		return err
+	}
+
+	if le := e.Localize(LocaleFromContext(ctx)); le != e {
+		return le
+	}
+
+	return err
+}
+
+// normalizeLocale normalizes the locale tag, e.g. zh_cn to zh-CN
+func normalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// parseAcceptLanguage parses the Accept-Language value into locale tags ordered by quality
+func parseAcceptLanguage(s string) []string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(part, ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if fv, err := strconv.ParseFloat(v, 64); err == nil {
+					q = fv
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{locale: normalizeLocale(locale), q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	locales := make([]string, 0, len(tags))
+	for _, t := range tags {
+		locales = append(locales, t.locale)
+	}
+
+	return locales
+}
+
+// matchLocale matches the tag exactly, then by its base language
+func matchLocale(locales []string, tag string) string {
+	for _, locale := range locales {
+		if locale == tag {
+			return locale
+		}
+	}
+
+	base := strings.SplitN(tag, "-", 2)[0]
+	for _, locale := range locales {
+		if strings.SplitN(locale, "-", 2)[0] == base {
+			return locale
+		}
+	}
+
+	return ""
+}
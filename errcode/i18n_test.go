@@ -0,0 +1,97 @@
+package errcode
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMatchLocale(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterMessages("zh_cn", map[uint32]string{20001: "用户不存在"})
+	r.RegisterMessages("ja", map[uint32]string{20001: "ユーザーが存在しません"})
+	assert.Equal(t, []string{DefaultLocale, "ja", LocaleZhCN}, r.Locales())
+
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"*", ""},
+		{"fr-FR", ""},
+		{"zh-CN", LocaleZhCN},
+		{"zh-TW,zh;q=0.9", LocaleZhCN},
+		{"en-US,en;q=0.9,zh-CN;q=0.8", DefaultLocale},
+		{"fr;q=0.9, ja;q=0.5, zh-CN;q=0.7", LocaleZhCN},
+		{"zh-CN;q=0, ja", "ja"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, r.MatchLocale(c.accept), c.accept)
+	}
+
+	msg, ok := r.Message(20001, "zh-cn")
+	assert.True(t, ok)
+	assert.Equal(t, "用户不存在", msg)
+}
+
+func TestErr_Localize(t *testing.T) {
+	assert.Same(t, ErrTokenExpire, ErrTokenExpire.Localize(""))
+	assert.Same(t, ErrTokenExpire, ErrTokenExpire.Localize(DefaultLocale))
+	assert.Same(t, ErrTokenExpire, ErrTokenExpire.Localize("fr"))
+
+	e := ErrTokenExpire.WithFieldViolation("token", "expired").Localize("zh-cn")
+	assert.Equal(t, "令牌已过期", e.Error())
+	assert.Equal(t, ErrTokenExpire.Code(), e.Code())
+	assert.Equal(t, ErrTokenExpire.HTTPCode(), e.HTTPCode())
+	assert.NotNil(t, e.Details())
+	assert.True(t, e.Is(ErrTokenExpire))
+	assert.Equal(t, "Expired token", ErrTokenExpire.Error())
+
+	// custom messages are not translated
+	custom := NewCustomErr("名称不能为空")
+	assert.Same(t, custom, custom.Localize(LocaleZhCN))
+	assert.Equal(t, "自定义错误", ErrCustom.Localize(LocaleZhCN).Error())
+}
+
+func TestLocaleFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", LocaleFromContext(ctx))
+	assert.Equal(t, "ja", LocaleFromContext(WithLocale(ctx, "ja")))
+
+	md := metadata.Pairs("accept-language", "zh-TW,zh;q=0.9")
+	assert.Equal(t, LocaleZhCN, LocaleFromContext(metadata.NewIncomingContext(ctx, md)))
+	md = metadata.Pairs("grpcgateway-accept-language", "zh-CN")
+	assert.Equal(t, LocaleZhCN, LocaleFromContext(metadata.NewIncomingContext(ctx, md)))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, "", LocaleFromRequest(r))
+	r.Header.Set(HeaderAcceptLanguage, "zh-CN,zh;q=0.9,en;q=0.8")
+	assert.Equal(t, LocaleZhCN, LocaleFromRequest(r))
+	r = r.WithContext(WithLocale(r.Context(), DefaultLocale))
+	assert.Equal(t, DefaultLocale, LocaleFromRequest(r))
+}
+
+func TestLocalize_Interceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "zh-CN"))
+
+	err := Localize(ctx, Wrap(context.Canceled, ErrInvalidParams))
+	assert.Equal(t, "参数非法", err.Error())
+	assert.Equal(t, context.Canceled, ParseErr(err).Unwrap())
+
+	_, err = UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, ErrTokenExpire
+	})
+	s, _ := status.FromError(err)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+	assert.Equal(t, "令牌已过期", s.Message())
+
+	e := ParseErr(err)
+	assert.Equal(t, ErrTokenExpire.Code(), e.Code())
+	assert.Equal(t, "令牌已过期", e.Error())
+}
@@ -0,0 +1,291 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ItemErrorDomain domain of the ErrorInfo details carrying the business errors of batch items,
+	// its reason is the business status code
+	ItemErrorDomain = "errcode.item"
+
+	// MetadataIndex ErrorInfo metadata key carrying the index of the batch item
+	MetadataIndex = "index"
+
+	// MetadataMessage ErrorInfo metadata key carrying the message of the batch item
+	MetadataMessage = "msg"
+
+	// MetadataDetails ErrorInfo metadata key carrying the JSON encoded details of the batch item
+	MetadataDetails = "details"
+)
+
+// ItemErr business error of a batch item
+type ItemErr struct {
+	Index int
+	Err   *Err
+}
+
+// MarshalJSON encodes the item error as index, code, msg and details
+func (ie ItemErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Index   int      `json:"index"`
+		Code    uint32   `json:"code"`
+		Msg     string   `json:"msg"`
+		Details *Details `json:"details,omitempty"`
+	}{Index: ie.Index, Code: ie.Err.code, Msg: ie.Err.msg, Details: ie.Err.details})
+}
+
+// Policy picks the overall business error of the batch from the item errors, which is never empty
+type Policy func(items []ItemErr) *Err
+
+// FirstPolicy picks the error of the first failed item
+func FirstPolicy(items []ItemErr) *Err {
+	return items[0].Err
+}
+
+// MostSeverePolicy picks the error with the highest HTTP status code, the first one on ties
+func MostSeverePolicy(items []ItemErr) *Err {
+	e := items[0].Err
+	for _, item := range items[1:] {
+		if item.Err.httpCode > e.httpCode {
+			e = item.Err
+		}
+	}
+
+	return e
+}
+
+// FixedPolicy always picks the business error e, such as a partial failure error
+func FixedPolicy(e *Err) Policy {
+	return func([]ItemErr) *Err {
+		return e
+	}
+}
+
+// MultiErr concurrent-safe aggregate of the business errors of batch items
+type MultiErr struct {
+	mu     sync.Mutex
+	items  []ItemErr
+	policy Policy
+}
+
+// NewMultiErr creates a new aggregate error, the overall error is picked by MostSeverePolicy if policy is not specified
+func NewMultiErr(policy ...Policy) *MultiErr {
+	m := &MultiErr{policy: MostSeverePolicy}
+	if len(policy) > 0 && policy[0] != nil {
+		m.policy = policy[0]
+	}
+
+	return m
+}
+
+// Add collects the error of the item at index, nil is ignored and other errors are parsed by ParseErr
+func (m *MultiErr) Add(index int, err error) {
+	if err == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.items = append(m.items, ItemErr{Index: index, Err: ParseErr(err)})
+	m.mu.Unlock()
+}
+
+// Len number of failed items
+func (m *MultiErr) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.items)
+}
+
+// Items returns the errors of the failed items in the order they were added
+func (m *MultiErr) Items() []ItemErr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]ItemErr(nil), m.items...)
+}
+
+// ErrOrNil returns the aggregate error, nil if no item failed
+func (m *MultiErr) ErrOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	return m
+}
+
+// Err returns the overall business error picked by the policy, NoErr if no item failed
+func (m *MultiErr) Err() *Err {
+	items := m.Items()
+	if len(items) == 0 {
+		return NoErr
+	}
+
+	return m.policy(items)
+}
+
+// Error message of the overall business error followed by the messages of the items
+func (m *MultiErr) Error() string {
+	items := m.Items()
+	if len(items) == 0 {
+		return NoErr.msg
+	}
+
+	var b strings.Builder
+	b.WriteString(m.policy(items).msg)
+	b.WriteString(": ")
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString("[" + strconv.Itoa(item.Index) + "] " + item.Err.msg)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the business errors of the items, so that errors.Is and errors.As match any of them
+func (m *MultiErr) Unwrap() []error {
+	items := m.Items()
+	errs := make([]error, 0, len(items))
+	for _, item := range items {
+		errs = append(errs, item.Err)
+	}
+
+	return errs
+}
+
+// Localize returns a copy of the aggregate error with the messages of the locale
+func (m *MultiErr) Localize(locale string) *MultiErr {
+	lm := &MultiErr{policy: m.policy}
+	for _, item := range m.Items() {
+		lm.items = append(lm.items, ItemErr{Index: item.Index, Err: item.Err.Localize(locale)})
+	}
+
+	return lm
+}
+
+// GRPCStatus converts the aggregate error to the gRPC status of the overall business error,
+// the errors of the items, including their details, are appended as ErrorInfo details
+func (m *MultiErr) GRPCStatus() *status.Status {
+	s := m.Err().GRPCStatus()
+	for _, item := range m.Items() {
+		info := &errdetails.ErrorInfo{
+			Reason: strconv.FormatUint(uint64(item.Err.code), 10),
+			Domain: ItemErrorDomain,
+			Metadata: map[string]string{
+				MetadataIndex:    strconv.Itoa(item.Index),
+				MetadataHTTPCode: strconv.Itoa(item.Err.httpCode),
+				MetadataMessage:  item.Err.msg,
+			},
+		}
+		if item.Err.details != nil {
+			if b, err := json.Marshal(item.Err.details); err == nil {
+				info.Metadata[MetadataDetails] = string(b)
+			}
+		}
+		if ds, err := s.WithDetails(info); err == nil {
+			s = ds
+		}
+	}
+
+	return s
+}
+
+// AsErr finds the outermost business error or aggregate error in the chain of err, at most one of them is non-nil,
+// so that a business error wrapping a batch failure takes precedence over the aggregate error
+func AsErr(err error) (*Err, *MultiErr) {
+	for err != nil {
+		switch v := err.(type) {
+		case *Err:
+			return v, nil
+		case *MultiErr:
+			return nil, v
+		case interface{ Unwrap() []error }:
+			for _, ue := range v.Unwrap() {
+				if e, m := AsErr(ue); e != nil || m != nil {
+					return e, m
+				}
+			}
+			return nil, nil
+		case interface{ Unwrap() error }:
+			err = v.Unwrap()
+		default:
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ParseMultiErr returns the aggregate error in the chain of err, reconstructing it from gRPC status errors,
+// the overall business error of the reconstructed aggregate error is fixed to the one of the status
+func ParseMultiErr(err error) (*MultiErr, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var m *MultiErr
+	if errors.As(err, &m) {
+		return m, true
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+
+	return multiErrFromStatus(s)
+}
+
+// multiErrFromStatus reconstructs the aggregate error from the item ErrorInfo details of the gRPC status
+func multiErrFromStatus(s *status.Status) (*MultiErr, bool) {
+	var items []ItemErr
+	for _, detail := range s.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != ItemErrorDomain {
+			continue
+		}
+
+		code, err := strconv.ParseUint(info.GetReason(), 10, 32)
+		if err != nil {
+			continue
+		}
+		md := info.GetMetadata()
+		index, _ := strconv.Atoi(md[MetadataIndex])
+		httpCode, err := strconv.Atoi(md[MetadataHTTPCode])
+		if err != nil {
+			httpCode = GRPCToHTTP(s.Code())
+		}
+
+		e := &Err{code: uint32(code), httpCode: httpCode, msg: md[MetadataMessage]}
+		if v, ok := md[MetadataDetails]; ok {
+			d := &Details{}
+			if err = json.Unmarshal([]byte(v), d); err == nil && !d.empty() {
+				e.details = d
+			}
+		}
+		if re, ok := std.ByCode(e.code); ok && re.msg == e.msg && re.httpCode == e.httpCode && e.details == nil {
+			e = re
+		}
+		items = append(items, ItemErr{Index: index, Err: e})
+	}
+	if len(items) == 0 {
+		return nil, false
+	}
+
+	overall, ok := fromStatus(s)
+	if !ok {
+		overall = NewGRPCErr(ErrUnexpected.code, s.Message(), s.Code())
+	}
+
+	return &MultiErr{items: items, policy: FixedPolicy(overall)}, true
+}
@@ -0,0 +1,168 @@
+package errcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMultiErr(t *testing.T) {
+	m := NewMultiErr()
+	assert.Nil(t, m.ErrOrNil())
+	assert.Same(t, NoErr, m.Err())
+
+	m.Add(0, nil)
+	m.Add(1, ErrInvalidParams.WithFieldViolation("name", "required"))
+	m.Add(3, fmt.Errorf("update: %w", ErrTokenExpire))
+	m.Add(4, errors.New("db down"))
+	require.Error(t, m.ErrOrNil())
+	assert.Equal(t, 3, m.Len())
+
+	items := m.Items()
+	assert.Equal(t, []int{1, 3, 4}, []int{items[0].Index, items[1].Index, items[2].Index})
+	assert.Same(t, ErrTokenExpire, items[1].Err)
+	assert.Same(t, ErrUnexpected, items[2].Err)
+
+	// errors.Is and errors.As match any item
+	var err error = m
+	assert.True(t, errors.Is(err, ErrInvalidParams))
+	assert.True(t, errors.Is(err, ErrTokenExpire))
+	assert.False(t, errors.Is(err, ErrUserLogin))
+	var got *MultiErr
+	assert.True(t, errors.As(fmt.Errorf("batch: %w", err), &got))
+	assert.Same(t, m, got)
+	assert.True(t, IsErr(err))
+
+	assert.Same(t, ErrUnexpected, m.Err())
+	assert.Same(t, ErrUnexpected, ParseErr(err))
+	assert.Equal(t, "Network error, please try again later: [1] Parameter is illegal; [3] Expired token; "+
+		"[4] Network error, please try again later", m.Error())
+
+	b, err := json.Marshal(items[:2])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"index":1,"code":10002,"msg":"Parameter is illegal","details":{"field_violations":[{"field":"name","description":"required"}]}},`+
+		`{"index":3,"code":10004,"msg":"Expired token"}]`, string(b))
+}
+
+func TestAsErr(t *testing.T) {
+	m := NewMultiErr()
+	m.Add(0, ErrTokenExpire)
+
+	// a business error wrapping the batch failure takes precedence over the aggregate error
+	partial := NewErr(20900, "Partial failure").WithCause(m)
+	e, am := AsErr(fmt.Errorf("batch: %w", partial))
+	assert.Same(t, partial, e)
+	assert.Nil(t, am)
+	assert.Same(t, partial, ParseErr(partial))
+	assert.Equal(t, "Partial failure", status.Convert(ToGRPCErr(partial)).Message())
+
+	e, am = AsErr(fmt.Errorf("batch: %w", m))
+	assert.Nil(t, e)
+	assert.Same(t, m, am)
+	assert.Same(t, ErrTokenExpire, ParseErr(m))
+
+	e, am = AsErr(errors.Join(errors.New("plain"), ErrInvalidParams, m))
+	assert.Same(t, ErrInvalidParams, e)
+	assert.Nil(t, am)
+
+	e, am = AsErr(errors.New("plain"))
+	assert.Nil(t, e)
+	assert.Nil(t, am)
+}
+
+func TestMultiErr_Policy(t *testing.T) {
+	add := func(m *MultiErr) *MultiErr {
+		m.Add(0, ErrInvalidParams)
+		m.Add(1, ErrTokenExpire)
+		m.Add(2, ErrInvalidHeader)
+		return m
+	}
+
+	assert.Same(t, ErrTokenExpire, add(NewMultiErr()).Err())
+	assert.Same(t, ErrInvalidParams, add(NewMultiErr(FirstPolicy)).Err())
+
+	errPartial := NewErr(20001, "Partial failure", http.StatusMultiStatus)
+	assert.Same(t, errPartial, add(NewMultiErr(FixedPolicy(errPartial))).Err())
+}
+
+func TestMultiErr_Concurrent(t *testing.T) {
+	m := NewMultiErr()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Add(i, ErrInvalidParams)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, m.Len())
+}
+
+func TestMultiErr_GRPC(t *testing.T) {
+	m := NewMultiErr()
+	m.Add(1, ErrInvalidParams)
+	m.Add(3, NewCustomErr("name is required"))
+	m.Add(5, ErrTokenExpire)
+	m.Add(7, ErrInvalidParams.WithFieldViolation("email", "invalid format").WithRetryDelay(time.Second))
+
+	err := ToGRPCErr(fmt.Errorf("batch: %w", m))
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+	assert.Equal(t, ErrTokenExpire.Error(), s.Message())
+
+	// the overall business error is parsed from the status
+	assert.Same(t, ErrTokenExpire, ParseErr(err))
+
+	pm, ok := ParseMultiErr(status.FromProto(s.Proto()).Err())
+	require.True(t, ok)
+	assert.Same(t, ErrTokenExpire, pm.Err())
+	items := pm.Items()
+	require.Len(t, items, 4)
+	assert.Equal(t, 1, items[0].Index)
+	assert.Same(t, ErrInvalidParams, items[0].Err)
+	assert.Equal(t, 3, items[1].Index)
+	assert.Equal(t, uint32(CodeCustom), items[1].Err.Code())
+	assert.Equal(t, "name is required", items[1].Err.Error())
+	assert.Same(t, ErrTokenExpire, items[2].Err)
+	// item details survive the round trip like they do in the HTTP JSON
+	assert.Equal(t, 7, items[3].Index)
+	assert.True(t, errors.Is(items[3].Err, ErrInvalidParams))
+	assert.Equal(t, &Details{
+		FieldViolations: []FieldViolation{{Field: "email", Description: "invalid format"}},
+		RetryDelayMs:    1000,
+	}, items[3].Err.Details())
+	assert.Nil(t, pm.Err().Details())
+
+	fm, ok := FromGRPCErr(err).(*MultiErr)
+	require.True(t, ok)
+	assert.Equal(t, 4, fm.Len())
+
+	_, ok = ParseMultiErr(ErrTokenExpire.GRPCStatus().Err())
+	assert.False(t, ok)
+	_, ok = ParseMultiErr(nil)
+	assert.False(t, ok)
+
+	// items are localized with the locale of the context
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "zh-CN"))
+	lm, ok := Localize(ctx, m).(*MultiErr)
+	require.True(t, ok)
+	assert.Equal(t, "参数非法", lm.Items()[0].Err.Error())
+	assert.Equal(t, "name is required", lm.Items()[1].Err.Error())
+	assert.Equal(t, "令牌已过期", lm.Err().Error())
+	assert.Equal(t, "Parameter is illegal", m.Items()[0].Err.Error())
+}
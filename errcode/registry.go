@@ -0,0 +1,221 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// CommonNamespace namespace of the business errors defined in this package
+	CommonNamespace = "common"
+
+	// CommonCodeMin minimum code reserved for the common namespace
+	CommonCodeMin = 0
+
+	// CommonCodeMax maximum code reserved for the common namespace, services should use codes above it
+	CommonCodeMax = 19999
+)
+
+// Entry a registered business error
+type Entry struct {
+	// Namespace namespace of the business error, empty for errors registered by SetCodeToErr
+	Namespace string
+
+	// Name name of the business error, unique within its namespace
+	Name string
+
+	// Err business error
+	Err *Err
+}
+
+// FullName name of the business error qualified by its namespace
+func (e Entry) FullName() string {
+	if e.Namespace == "" {
+		return e.Name
+	}
+
+	return e.Namespace + "." + e.Name
+}
+
+// Registry concurrent-safe registry of business errors, grouped by namespaces owning reserved code ranges
+type Registry struct {
+	mu         sync.RWMutex
+	namespaces map[string]*Namespace
+	codes      map[uint32]Entry
+	names      map[string]Entry
+	messages   map[string]map[uint32]string
+}
+
+// NewRegistry creates a new business error registry
+func NewRegistry() *Registry {
+	return &Registry{
+		namespaces: make(map[string]*Namespace),
+		codes:      make(map[uint32]Entry),
+		names:      make(map[string]Entry),
+		messages:   make(map[string]map[uint32]string),
+	}
+}
+
+// std default registry backing ParseCode, GetCodeToErr and SetCodeToErr
+var std = NewRegistry()
+
+// DefaultRegistry returns the default registry
+func DefaultRegistry() *Registry {
+	return std
+}
+
+// NewNamespace creates a namespace in the default registry reserving the code range [min, max]
+func NewNamespace(name string, min, max uint32) *Namespace {
+	return std.Namespace(name, min, max)
+}
+
+// Namespace creates a namespace reserving the code range [min, max],
+// panics if the name is taken or the range overlaps another namespace
+func (r *Registry) Namespace(name string, min, max uint32) *Namespace {
+	if name == "" || min > max {
+		panic(fmt.Sprintf("errcode: illegal namespace %q [%d, %d]", name, min, max))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.namespaces[name]; ok {
+		panic(fmt.Sprintf("errcode: namespace %q already exists", name))
+	}
+	for _, ns := range r.namespaces {
+		if min <= ns.max && ns.min <= max {
+			panic(fmt.Sprintf("errcode: namespace %q [%d, %d] overlaps namespace %q [%d, %d]",
+				name, min, max, ns.name, ns.min, ns.max))
+		}
+	}
+
+	ns := &Namespace{registry: r, name: name, min: min, max: max}
+	r.namespaces[name] = ns
+	return ns
+}
+
+// ByCode looks up the business error by code
+func (r *Registry) ByCode(code uint32) (*Err, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.codes[code]
+	return e.Err, ok
+}
+
+// ByName looks up the business error by the name qualified by its namespace, e.g. common.TokenExpire
+func (r *Registry) ByName(fullName string) (*Err, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.names[fullName]
+	return e.Err, ok
+}
+
+// Entries returns all registered business errors ordered by code
+func (r *Registry) Entries() []Entry {
+	return r.entries(func(Entry) bool { return true })
+}
+
+// Namespaces returns all namespaces ordered by their code ranges
+func (r *Registry) Namespaces() []*Namespace {
+	r.mu.RLock()
+	nss := make([]*Namespace, 0, len(r.namespaces))
+	for _, ns := range r.namespaces {
+		nss = append(nss, ns)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(nss, func(i, j int) bool { return nss[i].min < nss[j].min })
+	return nss
+}
+
+// entries returns the registered business errors matching f ordered by code
+func (r *Registry) entries(f func(Entry) bool) []Entry {
+	r.mu.RLock()
+	es := make([]Entry, 0, len(r.codes))
+	for _, e := range r.codes {
+		if f(e) {
+			es = append(es, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(es, func(i, j int) bool { return es[i].Err.code < es[j].Err.code })
+	return es
+}
+
+// add registers the entry under the code, returns an error if the code or name is taken
+func (r *Registry) add(code uint32, e Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.codes[code]; ok {
+		return fmt.Errorf("errcode: code %d of %q already registered by %q", code, e.FullName(), old.FullName())
+	}
+	if e.Name != "" {
+		if _, ok := r.names[e.FullName()]; ok {
+			return fmt.Errorf("errcode: name %q already registered", e.FullName())
+		}
+		r.names[e.FullName()] = e
+	}
+	r.codes[code] = e
+
+	return nil
+}
+
+// Namespace a group of business errors owning a reserved code range
+type Namespace struct {
+	registry *Registry
+	name     string
+	min, max uint32
+}
+
+// Name name of the namespace
+func (n *Namespace) Name() string {
+	return n.name
+}
+
+// Range reserved code range [min, max] of the namespace
+func (n *Namespace) Range() (min, max uint32) {
+	return n.min, n.max
+}
+
+// Register creates and registers a business error, intended to be called at init,
+// panics if the code is out of range or the code or name is taken
+func (n *Namespace) Register(name string, code uint32, msg string, httpCode ...int) *Err {
+	return n.MustAdd(name, NewErr(code, msg, httpCode...))
+}
+
+// MustAdd registers the business error, panics if the code is out of range or the code or name is taken
+func (n *Namespace) MustAdd(name string, e *Err) *Err {
+	if err := n.Add(name, e); err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// Add registers the business error, returns an error if the code is out of range or the code or name is taken
+func (n *Namespace) Add(name string, e *Err) error {
+	if name == "" {
+		return errors.New("errcode: empty name")
+	}
+	if e.code < n.min || e.code > n.max {
+		return fmt.Errorf("errcode: code %d of %q out of namespace range [%d, %d]", e.code, name, n.min, n.max)
+	}
+
+	return n.registry.add(e.code, Entry{Namespace: n.name, Name: name, Err: e})
+}
+
+// ByName looks up the business error of the namespace by name
+func (n *Namespace) ByName(name string) (*Err, bool) {
+	return n.registry.ByName(n.name + "." + name)
+}
+
+// Entries returns the business errors of the namespace ordered by code
+func (n *Namespace) Entries() []Entry {
+	return n.registry.entries(func(e Entry) bool { return e.Namespace == n.name })
+}
@@ -0,0 +1,108 @@
+package errcode
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	user := r.Namespace("user", 20000, 20999)
+	order := r.Namespace("order", 21000, 21999)
+
+	errUserNotFound := user.Register("NotFound", 20001, "User not found", http.StatusNotFound)
+	errOrderNotFound := order.Register("NotFound", 21001, "Order not found", http.StatusNotFound)
+	errOrderPaid := order.Register("Paid", 21002, "Order already paid")
+
+	e, ok := r.ByCode(20001)
+	assert.True(t, ok)
+	assert.Same(t, errUserNotFound, e)
+	_, ok = r.ByCode(20002)
+	assert.False(t, ok)
+
+	e, ok = r.ByName("order.NotFound")
+	assert.True(t, ok)
+	assert.Same(t, errOrderNotFound, e)
+	e, ok = user.ByName("NotFound")
+	assert.True(t, ok)
+	assert.Same(t, errUserNotFound, e)
+	_, ok = r.ByName("NotFound")
+	assert.False(t, ok)
+
+	assert.Equal(t, []Entry{
+		{Namespace: "user", Name: "NotFound", Err: errUserNotFound},
+		{Namespace: "order", Name: "NotFound", Err: errOrderNotFound},
+		{Namespace: "order", Name: "Paid", Err: errOrderPaid},
+	}, r.Entries())
+	assert.Equal(t, []Entry{
+		{Namespace: "order", Name: "NotFound", Err: errOrderNotFound},
+		{Namespace: "order", Name: "Paid", Err: errOrderPaid},
+	}, order.Entries())
+	assert.Equal(t, "order.Paid", order.Entries()[1].FullName())
+
+	nss := r.Namespaces()
+	require.Len(t, nss, 2)
+	assert.Equal(t, "user", nss[0].Name())
+	min, max := nss[1].Range()
+	assert.Equal(t, uint32(21000), min)
+	assert.Equal(t, uint32(21999), max)
+}
+
+func TestRegistry_Conflicts(t *testing.T) {
+	r := NewRegistry()
+	user := r.Namespace("user", 20000, 20999)
+	user.Register("NotFound", 20001, "User not found")
+
+	assert.Panics(t, func() { r.Namespace("user", 30000, 30999) })
+	assert.Panics(t, func() { r.Namespace("order", 20999, 21999) })
+	assert.Panics(t, func() { r.Namespace("order", 21999, 21000) })
+	assert.Panics(t, func() { user.Register("Disabled", 20001, "User disabled") })
+	assert.Panics(t, func() { user.Register("NotFound", 20002, "User not found") })
+	assert.Panics(t, func() { user.Register("Disabled", 21000, "User disabled") })
+
+	assert.Error(t, user.Add("", NewErr(20003, "User disabled")))
+	assert.NoError(t, user.Add("Disabled", NewErr(20003, "User disabled")))
+	assert.Len(t, user.Entries(), 2)
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := NewRegistry()
+	ns := r.Namespace("batch", 30000, 39999)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, ns.Add(fmt.Sprintf("Err%d", i), NewErr(uint32(30000+i), "batch error")))
+			_, _ = r.ByCode(uint32(30000 + i))
+			_ = r.Entries()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, ns.Entries(), 100)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	e, ok := DefaultRegistry().ByName("common.TokenExpire")
+	assert.True(t, ok)
+	assert.Same(t, ErrTokenExpire, e)
+	assert.Same(t, ErrTokenExpire, ParseCode(ErrTokenExpire.Code()))
+	assert.Same(t, ErrTWitterAddress, ParseCode(10011))
+
+	m := GetCodeToErr()
+	assert.Same(t, ErrInvalidParams, m[ErrInvalidParams.Code()])
+	assert.Same(t, NoErr, m[CodeOK])
+
+	// services reserve ranges above the common namespace
+	assert.Panics(t, func() { NewNamespace("overlap", CommonCodeMax, CommonCodeMax+1000) })
+
+	assert.Error(t, SetCodeToErr(ErrTokenExpire.Code(), NewErr(ErrTokenExpire.Code(), "legacy error")))
+	assert.Same(t, ErrTokenExpire, ParseCode(ErrTokenExpire.Code()))
+}
@@ -0,0 +1,107 @@
+package errcode
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// StatusClientClosedRequest non-standard HTTP status code of requests canceled by clients
+	StatusClientClosedRequest = 499
+)
+
+// GRPCCode gRPC canonical code of the business error, derived from the HTTP status code if not declared,
+// business errors returned with 2xx are considered failed preconditions
+func (e *Err) GRPCCode() codes.Code {
+	if e.grpcCode != codes.OK {
+		return e.grpcCode
+	}
+	if e.code == CodeOK {
+		return codes.OK
+	}
+
+	if c := HTTPToGRPC(e.httpCode); c != codes.OK {
+		return c
+	}
+
+	return codes.FailedPrecondition
+}
+
+// WithGRPCCode returns a copy of the business error declaring the gRPC canonical code
+func (e *Err) WithGRPCCode(c codes.Code) *Err {
+	err := *e
+	err.grpcCode = c
+	return &err
+}
+
+// HTTPToGRPC maps the HTTP status code to the gRPC canonical code
+func HTTPToGRPC(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case StatusClientClosedRequest:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+
+	switch {
+	case httpCode >= http.StatusOK && httpCode < http.StatusMultipleChoices:
+		return codes.OK
+	case httpCode >= http.StatusBadRequest && httpCode < http.StatusInternalServerError:
+		return codes.FailedPrecondition
+	case httpCode >= http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// GRPCToHTTP maps the gRPC canonical code to the HTTP status code
+func GRPCToHTTP(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return StatusClientClosedRequest
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,84 @@
+package errcode
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHTTPToGRPC(t *testing.T) {
+	cases := map[int]codes.Code{
+		http.StatusOK:                  codes.OK,
+		http.StatusNoContent:           codes.OK,
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusNotFound:            codes.NotFound,
+		http.StatusConflict:            codes.Aborted,
+		http.StatusTooManyRequests:     codes.ResourceExhausted,
+		StatusClientClosedRequest:      codes.Canceled,
+		http.StatusTeapot:              codes.FailedPrecondition,
+		http.StatusInternalServerError: codes.Internal,
+		http.StatusNotImplemented:      codes.Unimplemented,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+		http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+		http.StatusFound:               codes.Unknown,
+	}
+	for httpCode, want := range cases {
+		assert.Equal(t, want, HTTPToGRPC(httpCode), httpCode)
+	}
+}
+
+func TestGRPCToHTTP(t *testing.T) {
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		httpCode := GRPCToHTTP(c)
+		assert.True(t, httpCode == StatusClientClosedRequest || http.StatusText(httpCode) != "", c)
+
+		// codes sharing an HTTP status code map back to one of them
+		switch c {
+		case codes.OK, codes.Canceled, codes.InvalidArgument, codes.DeadlineExceeded, codes.NotFound,
+			codes.PermissionDenied, codes.ResourceExhausted, codes.Aborted, codes.Unimplemented,
+			codes.Internal, codes.Unavailable, codes.Unauthenticated:
+			assert.Equal(t, c, HTTPToGRPC(httpCode), c)
+		}
+	}
+	assert.Equal(t, http.StatusBadRequest, GRPCToHTTP(codes.FailedPrecondition))
+	assert.Equal(t, http.StatusConflict, GRPCToHTTP(codes.AlreadyExists))
+	assert.Equal(t, http.StatusInternalServerError, GRPCToHTTP(codes.Code(10004)))
+}
+
+func TestErr_GRPCCode(t *testing.T) {
+	assert.Equal(t, codes.OK, NoErr.GRPCCode())
+	assert.Equal(t, codes.FailedPrecondition, ErrCustom.GRPCCode())
+	assert.Equal(t, codes.FailedPrecondition, NewErr(20003, "frozen").GRPCCode())
+	assert.Equal(t, codes.Unauthenticated, ErrTokenExpire.GRPCCode())
+	assert.Equal(t, codes.Internal, ErrUnexpected.GRPCCode())
+
+	e := NewGRPCErr(20001, "not found", codes.NotFound)
+	assert.Equal(t, codes.NotFound, e.GRPCCode())
+	assert.Equal(t, http.StatusNotFound, e.HTTPCode())
+
+	e = NewGRPCErr(20002, "locked", codes.FailedPrecondition, http.StatusLocked)
+	assert.Equal(t, codes.FailedPrecondition, e.GRPCCode())
+	assert.Equal(t, http.StatusLocked, e.HTTPCode())
+
+	// the validation errors of the catalog declare InvalidArgument while keeping HTTP 200
+	assert.Equal(t, codes.InvalidArgument, ErrInvalidParams.GRPCCode())
+	assert.Equal(t, http.StatusOK, ErrInvalidParams.HTTPCode())
+	assert.Same(t, ErrInvalidParams, ParseErr(ErrInvalidParams.GRPCStatus().Err()))
+
+	e = ErrCustom.WithGRPCCode(codes.InvalidArgument)
+	assert.Equal(t, codes.InvalidArgument, e.GRPCCode())
+	assert.Equal(t, http.StatusOK, e.HTTPCode())
+	assert.Equal(t, codes.FailedPrecondition, ErrCustom.GRPCCode())
+
+	// declared codes survive the round trip
+	s := e.GRPCStatus()
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	pe := ParseErr(s.Err())
+	assert.Equal(t, codes.InvalidArgument, pe.GRPCCode())
+	assert.Equal(t, ErrCustom.Code(), pe.Code())
+	assert.NotSame(t, ErrCustom, pe)
+}
@@ -1,9 +1,11 @@
 package jwt
 
 import (
+	"crypto"
 	"encoding/base64"
 	"encoding/json"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -21,23 +23,65 @@ const (
 
 // Config JWT相关配置
 type Config struct {
-	Issuer         string        // 签发者
-	SecretKey      string        // 密钥
-	ExpirationTime time.Duration // 过期时间
+	Issuer         string            // 签发者
+	SecretKey      string            // 密钥，HS256签名算法使用
+	ExpirationTime time.Duration     // 过期时间
+	Algorithm      string            // 签名算法：HS256（默认）、RS256、ES256、EdDSA
+	KeyId          string            // 签名密钥id，写入令牌头部kid
+	PrivateKey     string            // PEM格式私钥，非对称签名算法签发令牌使用，仅验证令牌的服务无需配置
+	PublicKeys     map[string]string // 额外的验证公钥，key为密钥id，value为PEM格式公钥，用于密钥轮换期间验证其他密钥签发的令牌
 }
 
 // JWT JWT结构详情
 type JWT struct {
-	c *Config
+	c       *Config
+	method  jwt.SigningMethod
+	keys    *KeySet
+	mu      sync.RWMutex
+	kid     string
+	signKey crypto.PrivateKey
 }
 
 // NewJWT 新建JWT
 func NewJWT(c *Config) (*JWT, error) {
-	if c == nil || c.Issuer == "" || c.SecretKey == "" || c.ExpirationTime.Seconds() <= 0 {
+	if c == nil || c.Issuer == "" || c.ExpirationTime.Seconds() <= 0 {
 		return nil, errors.New("jwt: illegal jwt configure")
 	}
 
-	return &JWT{c: c}, nil
+	method, err := signingMethod(c.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &JWT{c: c, method: method, keys: NewKeySet()}
+	if method == jwt.SigningMethodHS256 {
+		if c.SecretKey == "" {
+			return nil, errors.New("jwt: illegal jwt configure")
+		}
+		if err = j.Rotate(c.KeyId, []byte(c.SecretKey)); err != nil {
+			return nil, err
+		}
+
+		return j, nil
+	}
+
+	if c.PrivateKey != "" {
+		if err = j.Rotate(c.KeyId, []byte(c.PrivateKey)); err != nil {
+			return nil, err
+		}
+	} else {
+		j.kid = c.KeyId
+	}
+	for kid, key := range c.PublicKeys {
+		if err = j.AddPublicKey(kid, []byte(key)); err != nil {
+			return nil, errors.WithMessagef(err, "key id %q", kid)
+		}
+	}
+	if len(j.keys.Ids()) == 0 {
+		return nil, errors.New("jwt: illegal jwt configure")
+	}
+
+	return j, nil
 }
 
 // MustNewJWT 新建JWT
@@ -73,8 +117,18 @@ func (j *JWT) CreateToken(token interface{}, expirationTime ...time.Duration) (s
 	// 私有载荷
 	claims[PrivatePayloadName] = base64.StdEncoding.EncodeToString(payload)
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ts, err := t.SignedString([]byte(j.c.SecretKey))
+	j.mu.RLock()
+	kid, signKey := j.kid, j.signKey
+	j.mu.RUnlock()
+	if signKey == nil {
+		return "", errors.New("jwt: no signing key")
+	}
+
+	t := jwt.NewWithClaims(j.method, claims)
+	if kid != "" {
+		t.Header["kid"] = kid
+	}
+	ts, err := t.SignedString(signKey)
 	if err != nil {
 		return "", errors.WithMessage(err, "sign token err")
 	}
@@ -86,7 +140,7 @@ func (j *JWT) CreateToken(token interface{}, expirationTime ...time.Duration) (s
 func (j *JWT) ParseToken(tokenStr string, token interface{}) error {
 	tokenStr = strings.Replace(tokenStr, "Bearer ", "", 1)
 
-	t, err := jwt.Parse(tokenStr, jwtKeyFunc(j.c.SecretKey))
+	t, err := jwt.Parse(tokenStr, j.keyFunc, jwt.WithValidMethods([]string{j.method.Alg()}))
 	if err != nil {
 		if e, ok := err.(*jwt.ValidationError); ok {
 			if e.Errors&jwt.ValidationErrorMalformed != 0 {
@@ -125,9 +179,56 @@ func (j *JWT) ParseToken(tokenStr string, token interface{}) error {
 	return nil
 }
 
-// jwtKeyFunc JWT签名密钥函数
-func jwtKeyFunc(key string) jwt.Keyfunc {
-	return func(token *jwt.Token) (interface{}, error) {
-		return []byte(key), nil
+// KeySet 获取验证密钥集合，可在运行时添加或移除验证密钥
+func (j *JWT) KeySet() *KeySet {
+	return j.keys
+}
+
+// AddPublicKey 按签名算法解析PEM格式公钥并添加到验证密钥集合
+func (j *JWT) AddPublicKey(kid string, key []byte) error {
+	pub, err := ParsePublicKey(j.method.Alg(), key)
+	if err != nil {
+		return err
 	}
+
+	j.keys.Add(kid, pub)
+	return nil
+}
+
+// Rotate 切换签名密钥，新密钥的验证密钥加入验证密钥集合，旧密钥的验证密钥保留，
+// 旧密钥签发的令牌全部过期后可通过KeySet().Remove移除
+func (j *JWT) Rotate(kid string, privateKey []byte) error {
+	key, err := ParsePrivateKey(j.method.Alg(), privateKey)
+	if err != nil {
+		return err
+	}
+
+	pub, err := publicKey(key)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys.Add(kid, pub)
+	j.kid, j.signKey = kid, key
+	j.mu.Unlock()
+
+	return nil
+}
+
+// keyFunc 按令牌头部kid选择验证密钥，未携带kid的令牌使用当前签名密钥id对应的验证密钥
+func (j *JWT) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		j.mu.RLock()
+		kid = j.kid
+		j.mu.RUnlock()
+	}
+
+	key, ok := j.keys.Get(kid)
+	if !ok {
+		return nil, errors.Errorf("jwt: unknown key id %q", kid)
+	}
+
+	return key, nil
 }
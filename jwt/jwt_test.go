@@ -1,11 +1,20 @@
 package jwt
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"cxqi/common/errcode"
 )
 
 func TestJWT_CreateToken(t *testing.T) {
@@ -69,3 +78,121 @@ func TestJWT_ParseToken(t *testing.T) {
 		t.Logf("%+v", parseToken)
 	}
 }
+
+// genKey 生成测试用PEM格式私钥与公钥
+func genKey(t *testing.T, alg string) (string, string) {
+	var (
+		priv interface{}
+		pub  interface{}
+		err  error
+	)
+	switch alg {
+	case AlgorithmRS256:
+		var k *rsa.PrivateKey
+		k, err = rsa.GenerateKey(rand.Reader, 2048)
+		priv, pub = k, &k.PublicKey
+	case AlgorithmES256:
+		var k *ecdsa.PrivateKey
+		k, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		priv, pub = k, &k.PublicKey
+	case AlgorithmEdDSA:
+		pub, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+}
+
+func TestJWT_Asymmetric(t *testing.T) {
+	type testToken struct {
+		UserId int64 `json:"user_id"`
+		RoleId int64 `json:"role_id"`
+	}
+	token := &testToken{UserId: 10000000, RoleId: 1}
+
+	for _, alg := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			priv, pub := genKey(t, alg)
+			signer, err := NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+				Algorithm: alg, KeyId: "k1", PrivateKey: priv})
+			require.NoError(t, err)
+
+			tokenStr, err := signer.CreateToken(token)
+			require.NoError(t, err)
+
+			// 仅持有公钥的服务验证令牌
+			verifier, err := NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+				Algorithm: alg, PublicKeys: map[string]string{"k1": pub}})
+			require.NoError(t, err)
+
+			parseToken := &testToken{}
+			require.NoError(t, verifier.ParseToken("Bearer "+tokenStr, parseToken))
+			assert.Equal(t, token, parseToken)
+
+			_, err = verifier.CreateToken(token)
+			assert.EqualError(t, err, "jwt: no signing key")
+		})
+	}
+}
+
+func TestJWT_Rotate(t *testing.T) {
+	priv1, pub1 := genKey(t, AlgorithmRS256)
+	priv2, pub2 := genKey(t, AlgorithmRS256)
+
+	signer, err := NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+		Algorithm: AlgorithmRS256, KeyId: "k1", PrivateKey: priv1})
+	require.NoError(t, err)
+	verifier, err := NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+		Algorithm: AlgorithmRS256, PublicKeys: map[string]string{"k1": pub1}})
+	require.NoError(t, err)
+
+	token := &Token{TokenType: TokenTypeAccess, UserId: 1}
+	oldTokenStr, err := signer.CreateToken(token)
+	require.NoError(t, err)
+
+	// 新密钥签发的令牌在验证方加入新公钥前无法通过验证
+	require.NoError(t, signer.Rotate("k2", []byte(priv2)))
+	newTokenStr, err := signer.CreateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, errcode.ErrTokenVerify, verifier.ParseToken(newTokenStr, &Token{}))
+
+	require.NoError(t, verifier.AddPublicKey("k2", []byte(pub2)))
+	assert.Equal(t, []string{"k1", "k2"}, verifier.KeySet().Ids())
+	assert.NoError(t, verifier.ParseToken(newTokenStr, &Token{}))
+	assert.NoError(t, verifier.ParseToken(oldTokenStr, &Token{}))
+
+	// 移除旧密钥后旧令牌失效
+	verifier.KeySet().Remove("k1")
+	assert.Equal(t, errcode.ErrTokenVerify, verifier.ParseToken(oldTokenStr, &Token{}))
+	assert.NoError(t, verifier.ParseToken(newTokenStr, &Token{}))
+}
+
+func TestJWT_ParseTokenAlgorithm(t *testing.T) {
+	priv, pub := genKey(t, AlgorithmRS256)
+	rs, err := NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+		Algorithm: AlgorithmRS256, PrivateKey: priv})
+	require.NoError(t, err)
+
+	// 以公钥作为HMAC密钥伪造的令牌不能通过验证
+	hs, err := NewJWT(&Config{Issuer: "gate-micro", SecretKey: pub, ExpirationTime: 72 * time.Hour})
+	require.NoError(t, err)
+	tokenStr, err := hs.CreateToken(&Token{UserId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.ErrTokenVerify, rs.ParseToken(tokenStr, &Token{}))
+
+	_, err = NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour, Algorithm: "none"})
+	assert.EqualError(t, err, `jwt: unsupported signing algorithm "none"`)
+
+	_, err = NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour, Algorithm: AlgorithmES256})
+	assert.EqualError(t, err, "jwt: illegal jwt configure")
+
+	_, err = NewJWT(&Config{Issuer: "gate-micro", ExpirationTime: 72 * time.Hour,
+		Algorithm: AlgorithmES256, PrivateKey: priv})
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AlgorithmHS256 签名算法：HMAC SHA-256，签发与验证使用同一密钥
+	AlgorithmHS256 = "HS256"
+	// AlgorithmRS256 签名算法：RSA SHA-256
+	AlgorithmRS256 = "RS256"
+	// AlgorithmES256 签名算法：ECDSA P-256 SHA-256
+	AlgorithmES256 = "ES256"
+	// AlgorithmEdDSA 签名算法：Ed25519
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// signingMethod 获取签名算法，为空时默认HS256
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case "", AlgorithmHS256:
+		return jwt.SigningMethodHS256, nil
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmES256:
+		return jwt.SigningMethodES256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.Errorf("jwt: unsupported signing algorithm %q", alg)
+	}
+}
+
+// ParsePrivateKey 按签名算法解析PEM格式私钥，HS256直接使用原始密钥
+func ParsePrivateKey(alg string, key []byte) (crypto.PrivateKey, error) {
+	if len(key) == 0 {
+		return nil, errors.New("jwt: empty private key")
+	}
+
+	switch alg {
+	case "", AlgorithmHS256:
+		return key, nil
+	case AlgorithmRS256:
+		k, err := jwt.ParseRSAPrivateKeyFromPEM(key)
+		return k, errors.WithMessage(err, "jwt: parse rsa private key err")
+	case AlgorithmES256:
+		k, err := jwt.ParseECPrivateKeyFromPEM(key)
+		if err != nil {
+			return nil, errors.WithMessage(err, "jwt: parse ecdsa private key err")
+		}
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("jwt: ES256 requires a P-256 key")
+		}
+		return k, nil
+	case AlgorithmEdDSA:
+		k, err := jwt.ParseEdPrivateKeyFromPEM(key)
+		return k, errors.WithMessage(err, "jwt: parse ed25519 private key err")
+	default:
+		return nil, errors.Errorf("jwt: unsupported signing algorithm %q", alg)
+	}
+}
+
+// ParsePublicKey 按签名算法解析PEM格式公钥或证书，HS256直接使用原始密钥
+func ParsePublicKey(alg string, key []byte) (crypto.PublicKey, error) {
+	if len(key) == 0 {
+		return nil, errors.New("jwt: empty public key")
+	}
+
+	switch alg {
+	case "", AlgorithmHS256:
+		return key, nil
+	case AlgorithmRS256:
+		k, err := jwt.ParseRSAPublicKeyFromPEM(key)
+		return k, errors.WithMessage(err, "jwt: parse rsa public key err")
+	case AlgorithmES256:
+		k, err := jwt.ParseECPublicKeyFromPEM(key)
+		if err != nil {
+			return nil, errors.WithMessage(err, "jwt: parse ecdsa public key err")
+		}
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("jwt: ES256 requires a P-256 key")
+		}
+		return k, nil
+	case AlgorithmEdDSA:
+		k, err := jwt.ParseEdPublicKeyFromPEM(key)
+		return k, errors.WithMessage(err, "jwt: parse ed25519 public key err")
+	default:
+		return nil, errors.Errorf("jwt: unsupported signing algorithm %q", alg)
+	}
+}
+
+// publicKey 获取私钥对应的验证密钥，HS256的验证密钥即为签名密钥
+func publicKey(key crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case []byte:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case crypto.Signer:
+		return k.Public(), nil
+	default:
+		return nil, errors.New("jwt: illegal private key")
+	}
+}
+
+// KeySet 验证密钥集合，按密钥id选择验证密钥，多个密钥可同时生效以实现无停机轮换，并发安全
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+// NewKeySet 新建验证密钥集合
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]crypto.PublicKey)}
+}
+
+// Add 添加或替换密钥id对应的验证密钥
+func (ks *KeySet) Add(kid string, key crypto.PublicKey) {
+	ks.mu.Lock()
+	ks.keys[kid] = key
+	ks.mu.Unlock()
+}
+
+// Remove 移除密钥id对应的验证密钥，该密钥签发的令牌将无法通过验证
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	delete(ks.keys, kid)
+	ks.mu.Unlock()
+}
+
+// Get 获取密钥id对应的验证密钥
+func (ks *KeySet) Get(kid string) (crypto.PublicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// Ids 获取所有密钥id，按字典序排列
+func (ks *KeySet) Ids() []string {
+	ks.mu.RLock()
+	ids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		ids = append(ids, kid)
+	}
+	ks.mu.RUnlock()
+
+	sort.Strings(ids)
+	return ids
+}
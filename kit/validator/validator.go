@@ -13,9 +13,14 @@ import (
 )
 
 const (
-	bankcardRegexString    = "^[0-9]{15,19}$"
+	// BankcardPattern 银行卡号匹配模式（不含首尾锚点）
+	BankcardPattern = "[0-9]{15,19}"
+	// IdcardPattern 身份证号匹配模式（不含首尾锚点）
+	IdcardPattern = "[0-9]{17}[0-9X]"
+
+	bankcardRegexString    = "^" + BankcardPattern + "$"
 	corpaccountRegexString = "^[0-9]{9,25}$"
-	idcardRegexString      = "^[0-9]{17}[0-9X]$"
+	idcardRegexString      = "^" + IdcardPattern + "$"
 	usccRegexString        = "^[A-Z0-9]{18}$"
 )
 
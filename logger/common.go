@@ -4,6 +4,7 @@ import (
 	"context"
 	"cxqi/common/errcode"
 	"io"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -12,12 +13,31 @@ import (
 
 // LogConf 配置信息
 type LogConf struct {
-	ServiceName string `toml:"service_name" mapstructure:"service_name" json:"service_name"`
-	Mode        string `toml:"mode" json:"mode"`
-	Path        string `toml:"path" json:"path"`
-	Level       string `toml:"level" json:"level"`
-	Compress    bool   `toml:"compress" json:"compress"`
-	KeepDays    int    `toml:"keep_days" mapstructure:"keep_days" json:"keep_days"`
+	ServiceName string       `toml:"service_name" mapstructure:"service_name" json:"service_name"`
+	Mode        string       `toml:"mode" json:"mode"`
+	Path        string       `toml:"path" json:"path"`
+	Level       string       `toml:"level" json:"level"`
+	Compress    bool         `toml:"compress" json:"compress"`
+	KeepDays    int          `toml:"keep_days" mapstructure:"keep_days" json:"keep_days"`
+	MaxSize     int          `toml:"max_size" mapstructure:"max_size" json:"max_size"`
+	Redact      bool         `toml:"redact" json:"redact"`
+	Sampling    SamplingConf `toml:"sampling" json:"sampling"`
+	Async       AsyncConf    `toml:"async" json:"async"`
+}
+
+// SamplingConf 日志采样配置，相同级别和消息的日志在每个采样周期内先记录Initial条，之后每Thereafter条记录1条
+type SamplingConf struct {
+	Interval   time.Duration `toml:"interval" json:"interval"`
+	Initial    int           `toml:"initial" json:"initial"`
+	Thereafter int           `toml:"thereafter" json:"thereafter"`
+	Dedup      bool          `toml:"dedup" json:"dedup"`
+}
+
+// AsyncConf 异步写入配置，缓冲区已满时按Policy阻塞（block）或丢弃（drop）日志
+type AsyncConf struct {
+	Enabled    bool   `toml:"enabled" json:"enabled"`
+	BufferSize int    `toml:"buffer_size" mapstructure:"buffer_size" json:"buffer_size"`
+	Policy     string `toml:"policy" json:"policy"`
 }
 
 // ErrorToCode 定义error 映射 code
@@ -29,14 +49,32 @@ func DefaultErrorToCode(err error) codes.Code {
 		return codes.OK
 	}
 
-	switch e := err.(type) {
-	case interface{ GRPCStatus() *status.Status }:
-		return status.Code(err)
-	case *errcode.Err:
-		return codes.Code(e.Code())
-	default:
-		return codes.Unknown
+	if e, m := errcode.AsErr(err); e != nil {
+		return e.GRPCCode()
+	} else if m != nil {
+		return m.Err().GRPCCode()
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+
+	return codes.Unknown
+}
+
+// ErrorBizCode 获取错误链中最外层业务错误的业务状态码，聚合错误取其整体业务错误
+func ErrorBizCode(err error) (uint32, bool) {
+	if err == nil {
+		return 0, false
 	}
+
+	if e, m := errcode.AsErr(err); e != nil {
+		return e.Code(), true
+	} else if m != nil {
+		return m.Err().Code(), true
+	}
+
+	return 0, false
 }
 
 // Decider 决策器 定义抑制拦截器日志的规则
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"cxqi/common/errcode"
+)
+
+func TestDefaultErrorToCode(t *testing.T) {
+	assert.Equal(t, codes.OK, DefaultErrorToCode(nil))
+	assert.Equal(t, codes.Internal, DefaultErrorToCode(errcode.ErrUnexpected))
+	assert.Equal(t, codes.Unauthenticated, DefaultErrorToCode(errcode.Wrap(errors.New("expired"), errcode.ErrTokenExpire)))
+	assert.Equal(t, codes.NotFound, DefaultErrorToCode(status.Error(codes.NotFound, "not found")))
+	assert.Equal(t, codes.Unknown, DefaultErrorToCode(errors.New("unknown")))
+
+	m := errcode.NewMultiErr()
+	m.Add(0, errcode.ErrTokenExpire)
+	m.Add(1, errcode.ErrUnexpected)
+	assert.Equal(t, codes.Internal, DefaultErrorToCode(m))
+}
+
+func TestErrorBizCode(t *testing.T) {
+	code, ok := ErrorBizCode(errcode.Wrap(errors.New("expired"), errcode.ErrTokenExpire))
+	assert.True(t, ok)
+	assert.Equal(t, errcode.ErrTokenExpire.Code(), code)
+
+	_, ok = ErrorBizCode(status.Error(codes.NotFound, "not found"))
+	assert.False(t, ok)
+	_, ok = ErrorBizCode(nil)
+	assert.False(t, ok)
+}
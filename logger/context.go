@@ -1,6 +1,20 @@
 package logger
 
-import "context"
+import (
+	"context"
+	"sync"
+
+	"cxqi/common/kit/convert"
+)
+
+const (
+	// TagUserId 用户id标签
+	TagUserId = "user_id"
+	// TagTraceId 链路追踪id标签
+	TagTraceId = "trace_id"
+	// TagClientIP 客户端IP标签
+	TagClientIP = "client_ip"
+)
 
 type ctxMarker struct{}
 
@@ -21,23 +35,81 @@ type Tags interface {
 	Values() map[string]interface{}
 }
 
-// mapTags Tags map结构体
+// mapTags 并发安全的Tags map结构体，子tags可读取父tags的字段，写入和删除只作用于自身
 type mapTags struct {
-	values map[string]interface{}
+	mu      sync.RWMutex
+	parent  Tags
+	values  map[string]interface{}
+	deleted map[string]struct{}
 }
 
+// Set 设置字段
 func (t *mapTags) Set(key string, value interface{}) Tags {
+	t.mu.Lock()
 	t.values[key] = value
+	delete(t.deleted, key)
+	t.mu.Unlock()
+
 	return t
 }
 
+// Has 判断字段是否存在
 func (t *mapTags) Has(key string) bool {
-	_, ok := t.values[key]
+	_, ok := t.Get(key)
 	return ok
 }
 
+// Get 获取字段
+func (t *mapTags) Get(key string) (interface{}, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if v, ok := t.values[key]; ok {
+		return v, true
+	}
+	if _, ok := t.deleted[key]; ok || t.parent == nil {
+		return nil, false
+	}
+
+	return Get(t.parent, key)
+}
+
+// Delete 删除字段，父tags中的同名字段只在当前tags中不可见
+func (t *mapTags) Delete(key string) Tags {
+	t.mu.Lock()
+	delete(t.values, key)
+	if t.parent != nil && t.parent.Has(key) {
+		if t.deleted == nil {
+			t.deleted = make(map[string]struct{})
+		}
+		t.deleted[key] = struct{}{}
+	}
+	t.mu.Unlock()
+
+	return t
+}
+
+// Values 返回包含父tags字段的全部字段快照
 func (t *mapTags) Values() map[string]interface{} {
-	return t.values
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var pvs map[string]interface{}
+	if t.parent != nil {
+		pvs = t.parent.Values()
+	}
+
+	values := make(map[string]interface{}, len(pvs)+len(t.values))
+	for k, v := range pvs {
+		if _, ok := t.deleted[k]; !ok {
+			values[k] = v
+		}
+	}
+	for k, v := range t.values {
+		values[k] = v
+	}
+
+	return values
 }
 
 type noopTags struct{}
@@ -50,6 +122,14 @@ func (t *noopTags) Has(key string) bool {
 	return false
 }
 
+func (t *noopTags) Get(key string) (interface{}, bool) {
+	return nil, false
+}
+
+func (t *noopTags) Delete(key string) Tags {
+	return t
+}
+
 func (t *noopTags) Values() map[string]interface{} {
 	return nil
 }
@@ -73,3 +153,73 @@ func SetInContext(ctx context.Context, tags Tags) context.Context {
 func NewTags() Tags {
 	return &mapTags{values: make(map[string]interface{})}
 }
+
+// NewChildTags 新建子tags，子tags可读取父tags的字段，写入和删除不影响父tags
+func NewChildTags(parent Tags) Tags {
+	if parent == nil || parent == NoopTags {
+		return NewTags()
+	}
+
+	return &mapTags{parent: parent, values: make(map[string]interface{})}
+}
+
+// WithChildTags 添加上下文中tags的子tags到上下文，用于在协程中添加字段而不污染请求的tags
+func WithChildTags(ctx context.Context) context.Context {
+	return SetInContext(ctx, NewChildTags(Extract(ctx)))
+}
+
+// Get 获取tags中的字段
+func Get(t Tags, key string) (interface{}, bool) {
+	if g, ok := t.(interface {
+		Get(key string) (interface{}, bool)
+	}); ok {
+		return g.Get(key)
+	}
+
+	if !t.Has(key) {
+		return nil, false
+	}
+	v, ok := t.Values()[key]
+	return v, ok
+}
+
+// GetString 获取tags中的字段并转换为string
+func GetString(t Tags, key string) (string, bool) {
+	v, ok := Get(t, key)
+	if !ok {
+		return "", false
+	}
+
+	return convert.ToString(v), true
+}
+
+// GetInt64 获取tags中的字段并转换为int64
+func GetInt64(t Tags, key string) (int64, bool) {
+	v, ok := Get(t, key)
+	if !ok {
+		return 0, false
+	}
+
+	return convert.ToInt64(v), true
+}
+
+// GetBool 获取tags中的字段并转换为bool
+func GetBool(t Tags, key string) (bool, bool) {
+	v, ok := Get(t, key)
+	if !ok {
+		return false, false
+	}
+
+	return convert.ToBool(v), true
+}
+
+// Delete 删除tags中的字段，tags不支持删除时不做任何操作
+func Delete(t Tags, key string) Tags {
+	if d, ok := t.(interface {
+		Delete(key string) Tags
+	}); ok {
+		return d.Delete(key)
+	}
+
+	return t
+}
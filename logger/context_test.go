@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	tags := NewTags().Set("user_id", int64(1000)).Set("name", "sliver")
+	assert.True(t, tags.Has("user_id"))
+	assert.Equal(t, map[string]interface{}{"user_id": int64(1000), "name": "sliver"}, tags.Values())
+
+	// 修改返回的map不影响tags
+	tags.Values()["other"] = 1
+	assert.False(t, tags.Has("other"))
+
+	Delete(tags, "name")
+	assert.False(t, tags.Has("name"))
+
+	userId, ok := GetInt64(tags, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), userId)
+
+	s, ok := GetString(tags, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, "1000", s)
+
+	_, ok = GetBool(tags, "unknown")
+	assert.False(t, ok)
+}
+
+func TestNewChildTags(t *testing.T) {
+	parent := NewTags().Set("user_id", int64(1000)).Set("trace_id", "abc")
+	ctx := WithChildTags(SetInContext(context.Background(), parent))
+	child := Extract(ctx)
+
+	child.Set("task", "sync")
+	Delete(child, "trace_id")
+
+	assert.True(t, child.Has("user_id"))
+	assert.False(t, child.Has("trace_id"))
+	assert.Equal(t, map[string]interface{}{"user_id": int64(1000), "task": "sync"}, child.Values())
+	assert.Equal(t, map[string]interface{}{"user_id": int64(1000), "trace_id": "abc"}, parent.Values())
+
+	child.Set("trace_id", "def")
+	v, _ := GetString(child, "trace_id")
+	assert.Equal(t, "def", v)
+
+	// 父tags新增字段对子tags可见
+	parent.Set("client_ip", "127.0.0.1")
+	assert.True(t, child.Has("client_ip"))
+
+	assert.Equal(t, map[string]interface{}{}, NewChildTags(NoopTags).Values())
+}
+
+func TestTags_Concurrent(t *testing.T) {
+	tags := NewTags()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := NewChildTags(tags)
+			for j := 0; j < 100; j++ {
+				key := strconv.Itoa(i) + "_" + strconv.Itoa(j)
+				tags.Set(key, j)
+				child.Set(key, j)
+				_ = tags.Values()
+				_ = child.Values()
+				Delete(tags, key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Empty(t, tags.Values())
+}
+
+func TestNoopTags(t *testing.T) {
+	tags := Extract(context.Background())
+	assert.Equal(t, NoopTags, tags)
+	assert.False(t, tags.Set("key", "value").Has("key"))
+	assert.Nil(t, tags.Values())
+	_, ok := Get(tags, "key")
+	assert.False(t, ok)
+	assert.Equal(t, NoopTags, Delete(tags, "key"))
+}
@@ -12,4 +12,18 @@ type Logger interface {
 	Errorf(format string, data ...interface{})
 	// Panicf panic
 	Panicf(format string, data ...interface{})
+
+	// Debugw debug，附带键值对字段
+	Debugw(msg string, keysAndValues ...interface{})
+	// Infow info，附带键值对字段
+	Infow(msg string, keysAndValues ...interface{})
+	// Warnw warn，附带键值对字段
+	Warnw(msg string, keysAndValues ...interface{})
+	// Errorw error，附带键值对字段
+	Errorw(msg string, keysAndValues ...interface{})
+	// Panicw panic，附带键值对字段
+	Panicw(msg string, keysAndValues ...interface{})
+
+	// With 返回附带键值对字段的日志记录器
+	With(keysAndValues ...interface{}) Logger
 }
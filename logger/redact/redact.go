@@ -0,0 +1,558 @@
+package redact
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cxqi/common/kit/validator"
+)
+
+const (
+	// TagName 脱敏结构体标签名称
+	TagName = "log"
+	// TagMask 脱敏结构体标签值，如 `log:"mask"`
+	TagMask = "mask"
+	// Mask 脱敏后的替换内容
+	Mask = "******"
+	// Truncated 循环引用或超出最大深度的值的替换内容
+	Truncated = "..."
+	// MaxDepth 脱敏任意值时的最大递归深度
+	MaxDepth = 32
+)
+
+var (
+	// DefaultKeys 默认的敏感字段名称，匹配时忽略大小写、下划线和中划线
+	DefaultKeys = []string{
+		"password", "passwd", "pwd", "secret", "secret_key", "token",
+		"access_token", "refresh_token", "authorization", "api_key",
+	}
+
+	// DefaultRules 默认的正则脱敏规则
+	DefaultRules = []Rule{
+		NewRule("bearer", `(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`, func(string) string { return "Bearer " + Mask }),
+		NewRule("jwt", `\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`, func(string) string { return Mask }),
+		NewRule("idcard", `\b`+validator.IdcardPattern+`\b`, partialIdcard),
+		NewRule("bankcard", `\b`+validator.BankcardPattern+`\b`, partialBankcard),
+	}
+
+	std = New(DefaultKeys, DefaultKeys, DefaultRules...)
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+
+	// keyReplacer 规范化字段名称时移除下划线和中划线
+	keyReplacer = strings.NewReplacer("_", "", "-", "")
+)
+
+// Rule 正则脱敏规则
+type Rule struct {
+	Name    string
+	Regexp  *regexp.Regexp
+	Replace func(s string) string
+}
+
+// NewRule 新建正则脱敏规则，replace为空时部分脱敏
+func NewRule(name, pattern string, replace func(s string) string) Rule {
+	if replace == nil {
+		replace = Partial
+	}
+
+	return Rule{Name: name, Regexp: regexp.MustCompile(pattern), Replace: replace}
+}
+
+// partialIdcard 部分脱敏出生日期合法且通过GB 11643校验码校验的身份证号，其余18位数字串保持原样
+func partialIdcard(s string) string {
+	if !idcardValid(s) {
+		return s
+	}
+
+	return Partial(s)
+}
+
+// idcardWeights GB 11643身份证号前17位的加权因子
+var idcardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// idcardValid 校验18位身份证号的出生日期和校验码
+func idcardValid(s string) bool {
+	if len(s) != 18 {
+		return false
+	}
+	if _, err := time.Parse("20060102", s[6:14]); err != nil || s[6:8] < "18" || s[6:8] > "20" {
+		return false
+	}
+
+	sum := 0
+	for i, w := range idcardWeights {
+		sum += int(s[i]-'0') * w
+	}
+
+	return "10X98765432"[sum%11] == s[17]
+}
+
+// partialBankcard 部分脱敏以常见发卡行标识（3、4、5、6开头）开头且通过Luhn校验的银行卡号，
+// 以降低订单号、雪花id、纳秒时间戳等数字串被误判的概率，无法完全避免
+func partialBankcard(s string) string {
+	if s[0] < '3' || s[0] > '6' || !luhn(s) {
+		return s
+	}
+
+	return Partial(s)
+}
+
+// luhn Luhn校验
+func luhn(s string) bool {
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		d := int(s[len(s)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+// Redactor 脱敏器，支持结构体标签、敏感字段名称、正则规则和sql绑定值脱敏
+type Redactor struct {
+	mu      sync.RWMutex
+	keys    map[string]struct{}
+	columns map[string]struct{}
+	rules   []Rule
+}
+
+// New 新建脱敏器，keys为敏感字段名称，columns为需要脱敏绑定值的sql列名称
+func New(keys, columns []string, rules ...Rule) *Redactor {
+	r := &Redactor{keys: map[string]struct{}{}, columns: map[string]struct{}{}}
+	r.AddKeys(keys...)
+	r.AddColumns(columns...)
+	r.AddRules(rules...)
+
+	return r
+}
+
+// AddKeys 添加敏感字段名称
+func (r *Redactor) AddKeys(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range keys {
+		r.keys[normalize(k)] = struct{}{}
+	}
+}
+
+// AddColumns 添加需要脱敏绑定值的sql列名称
+func (r *Redactor) AddColumns(columns ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range columns {
+		r.columns[normalize(c)] = struct{}{}
+	}
+}
+
+// AddRules 添加正则脱敏规则
+func (r *Redactor) AddRules(rules ...Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = append(r.rules, rules...)
+}
+
+// SetRules 替换全部正则脱敏规则
+func (r *Redactor) SetRules(rules ...Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = append([]Rule(nil), rules...)
+}
+
+// IsSensitiveKey 判断是否为敏感字段名称
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.keys[normalize(key)]
+	return ok
+}
+
+// isSensitiveColumn 判断是否为需要脱敏绑定值的sql列名称
+func (r *Redactor) isSensitiveColumn(column string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.columns[normalize(strings.Trim(column, "`\" "))]
+	return ok
+}
+
+// String 按正则规则脱敏字符串
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	rules := r.rules
+	r.mu.RUnlock()
+
+	for _, rule := range rules {
+		s = rule.Regexp.ReplaceAllStringFunc(s, rule.Replace)
+	}
+
+	return s
+}
+
+// Value 脱敏任意值，结构体转换为以json名称为键的map，
+// 带有`log:"mask"`标签或名称为敏感字段的值被替换，字符串按正则规则脱敏
+func (r *Redactor) Value(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	return r.value(reflect.ValueOf(v), 0, map[uintptr]struct{}{})
+}
+
+// value 递归脱敏反射值，visiting为当前递归路径上的指针和map，用于识别循环引用
+func (r *Redactor) value(v reflect.Value, depth int, visiting map[uintptr]struct{}) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > MaxDepth {
+		return Truncated
+	}
+
+	t := v.Type()
+	if t.Kind() != reflect.String && (t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		// json.Number为数值，不按正则规则脱敏，避免数值型id被改写为字符串
+		if t == jsonNumberType {
+			return v.Interface()
+		}
+		if s := r.String(v.String()); s != v.String() {
+			return s
+		}
+		return v.Interface()
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return r.value(v.Elem(), depth+1, visiting)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		ptr := v.Pointer()
+		if _, ok := visiting[ptr]; ok {
+			return Truncated
+		}
+		visiting[ptr] = struct{}{}
+		defer delete(visiting, ptr)
+		return r.value(v.Elem(), depth+1, visiting)
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			name, omitempty := jsonName(sf)
+			if name == "-" {
+				continue
+			}
+
+			fv := v.Field(i)
+			if omitempty && fv.IsZero() {
+				continue
+			}
+			if sf.Tag.Get(TagName) == TagMask || r.IsSensitiveKey(name) {
+				m[name] = Mask
+				continue
+			}
+			m[name] = r.value(fv, depth+1, visiting)
+		}
+		return m
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		if v.IsNil() {
+			return nil
+		}
+		ptr := v.Pointer()
+		if _, ok := visiting[ptr]; ok {
+			return Truncated
+		}
+		visiting[ptr] = struct{}{}
+		defer delete(visiting, ptr)
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			if r.IsSensitiveKey(k) {
+				m[k] = Mask
+				continue
+			}
+			m[k] = r.value(iter.Value(), depth+1, visiting)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = r.value(v.Index(i), depth+1, visiting)
+		}
+		return s
+	default:
+		return v.Interface()
+	}
+}
+
+// JSON 脱敏json，敏感字段的值被替换，字符串按正则规则脱敏，非法json按json片段脱敏
+func (r *Redactor) JSON(b []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return []byte(r.JSONFragment(string(b)))
+	}
+
+	out, err := json.Marshal(r.Value(v))
+	if err != nil {
+		return []byte(r.JSONFragment(string(b)))
+	}
+
+	return out
+}
+
+var (
+	// jsonPairRegex json键值对，值可能因截断缺少结尾引号，对象和数组值不匹配而由其内部的键值对匹配
+	jsonPairRegex = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,{}\[\]"]+)`)
+	// jsonStringRegex json字符串，可能因截断缺少结尾引号
+	jsonStringRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"?`)
+)
+
+// JSONFragment 脱敏无法解析的json片段，如被截断的请求体，敏感字段的值被替换，
+// 只有字符串按正则规则脱敏，数值保持原样，不以json字符开头的内容按字符串脱敏
+func (r *Redactor) JSONFragment(s string) string {
+	if t := strings.TrimSpace(s); t == "" || !strings.ContainsRune("{[\"", rune(t[0])) {
+		return r.String(s)
+	}
+
+	s = jsonPairRegex.ReplaceAllStringFunc(s, func(m string) string {
+		sub := jsonPairRegex.FindStringSubmatch(m)
+		if !r.IsSensitiveKey(sub[1]) {
+			return m
+		}
+		return `"` + sub[1] + `"` + sub[2] + `"` + Mask + `"`
+	})
+
+	return jsonStringRegex.ReplaceAllStringFunc(s, r.String)
+}
+
+// SQL 脱敏sql语句中敏感列的绑定值，包括比较、赋值和insert语句的values
+func (r *Redactor) SQL(sql string) string {
+	sql = sqlCmpRegex.ReplaceAllStringFunc(sql, func(s string) string {
+		sub := sqlCmpRegex.FindStringSubmatch(s)
+		if !r.isSensitiveColumn(sub[2]) {
+			return s
+		}
+		if strings.HasPrefix(sub[3], "(") {
+			return sub[1] + "('" + Mask + "')"
+		}
+		return sub[1] + "'" + Mask + "'"
+	})
+
+	return r.insertSQL(sql)
+}
+
+var (
+	// sqlCmpRegex sql列的比较和赋值
+	sqlCmpRegex = regexp.MustCompile("(?i)((?:\\b|[`\"])(\\w+)[`\"]?\\s*(?:=|!=|<>|<=|>=|<|>|\\bLIKE\\b|\\bIN\\b)\\s*)" +
+		"('(?:[^'\\\\]|\\\\.|'')*'|\"(?:[^\"\\\\]|\\\\.)*\"|\\([^)]*\\)|[-+]?[0-9][0-9.]*)")
+	// insertRegex insert语句
+	insertRegex = regexp.MustCompile("(?is)^(\\s*INSERT\\s+INTO\\s+[^(]+\\(([^)]*)\\)\\s*VALUES\\s*)(.*)$")
+)
+
+// insertSQL 脱敏insert语句中敏感列的values
+func (r *Redactor) insertSQL(sql string) string {
+	sub := insertRegex.FindStringSubmatch(sql)
+	if sub == nil {
+		return sql
+	}
+
+	columns := strings.Split(sub[2], ",")
+	sensitive := make([]bool, len(columns))
+	var found bool
+	for i, c := range columns {
+		sensitive[i] = r.isSensitiveColumn(c)
+		found = found || sensitive[i]
+	}
+	if !found {
+		return sql
+	}
+
+	var (
+		buf     strings.Builder
+		values  = sub[3]
+		depth   int
+		index   int
+		quote   byte
+		masking bool
+	)
+	buf.WriteString(sub[1])
+	for i := 0; i < len(values); i++ {
+		c := values[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(values) {
+				if !masking {
+					buf.WriteByte(c)
+					buf.WriteByte(values[i+1])
+				}
+				i++
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+			if depth == 1 {
+				index = 0
+				buf.WriteByte(c)
+				masking = index < len(sensitive) && sensitive[index]
+				if masking {
+					buf.WriteString("'" + Mask + "'")
+				}
+				continue
+			}
+		case c == ')':
+			depth--
+			if depth == 0 {
+				masking = false
+			}
+		case c == ',' && depth == 1:
+			index++
+			buf.WriteByte(c)
+			masking = index < len(sensitive) && sensitive[index]
+			if masking {
+				buf.WriteString("'" + Mask + "'")
+			}
+			continue
+		case depth == 0 && c != ',' && c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			// values之后的语句原样保留
+			buf.WriteString(values[i:])
+			return buf.String()
+		}
+
+		if !masking {
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String()
+}
+
+// Partial 部分脱敏，保留前3位和后4位，长度不足时全部替换
+func Partial(s string) string {
+	n := utf8.RuneCountInString(s)
+	if n < 11 {
+		return Mask
+	}
+
+	rs := []rune(s)
+	return string(rs[:3]) + strings.Repeat("*", n-7) + string(rs[n-4:])
+}
+
+// AddKeys 添加全局脱敏器的敏感字段名称
+func AddKeys(keys ...string) {
+	std.AddKeys(keys...)
+}
+
+// AddColumns 添加全局脱敏器需要脱敏绑定值的sql列名称
+func AddColumns(columns ...string) {
+	std.AddColumns(columns...)
+}
+
+// AddRules 添加全局脱敏器的正则脱敏规则
+func AddRules(rules ...Rule) {
+	std.AddRules(rules...)
+}
+
+// SetRules 替换全局脱敏器的全部正则脱敏规则
+func SetRules(rules ...Rule) {
+	std.SetRules(rules...)
+}
+
+// IsSensitiveKey 判断是否为全局脱敏器的敏感字段名称
+func IsSensitiveKey(key string) bool {
+	return std.IsSensitiveKey(key)
+}
+
+// String 使用全局脱敏器脱敏字符串
+func String(s string) string {
+	return std.String(s)
+}
+
+// Value 使用全局脱敏器脱敏任意值
+func Value(v interface{}) interface{} {
+	return std.Value(v)
+}
+
+// JSON 使用全局脱敏器脱敏json
+func JSON(b []byte) []byte {
+	return std.JSON(b)
+}
+
+// JSONFragment 使用全局脱敏器脱敏json片段
+func JSONFragment(s string) string {
+	return std.JSONFragment(s)
+}
+
+// SQL 使用全局脱敏器脱敏sql语句
+func SQL(sql string) string {
+	return std.SQL(sql)
+}
+
+// jsonName 获取结构体字段的json名称
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "" {
+		return sf.Name, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+	if tag == "-" {
+		name = "-"
+	}
+
+	return name, strings.Contains(","+opts+",", ",omitempty,")
+}
+
+// normalize 规范化字段名称，忽略大小写、下划线和中划线
+func normalize(key string) string {
+	return keyReplacer.Replace(strings.ToLower(key))
+}
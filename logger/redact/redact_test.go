@@ -0,0 +1,157 @@
+package redact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	Name     string    `json:"name"`
+	Password string    `json:"password"`
+	Idcard   string    `json:"idcard" log:"mask"`
+	Remark   string    `json:"remark,omitempty"`
+	Ignored  string    `json:"-"`
+	Created  time.Time `json:"created"`
+	Cards    []card    `json:"cards"`
+	internal string
+}
+
+type card struct {
+	No string `json:"no"`
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "card 622************1233 end", String("card 6222021234567891233 end"))
+	assert.Equal(t, "id 110***********002X", String("id 11010519491231002X"))
+	// 出生日期或校验码非法的18位数字串、非常见发卡行标识开头的数字串不脱敏
+	assert.Equal(t, "order 123456789012345678", String("order 123456789012345678"))
+	assert.Equal(t, "id 110105194912310021", String("id 110105194912310021"))
+	assert.Equal(t, "ts 1729584000123456789", String("ts 1729584000123456789"))
+	assert.Equal(t, "Authorization: Bearer "+Mask, String("Authorization: Bearer abc.def-123"))
+	assert.Equal(t, "token "+Mask, String("token eyJhbGciOiJIUzI1NiJ9.eyJ1aWQiOjF9.c2lnbmF0dXJl"))
+	assert.Equal(t, "order 12345", String("order 12345"))
+	assert.Equal(t, "order 1234567890123456789", String("order 1234567890123456789"))
+}
+
+func TestValue(t *testing.T) {
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := Value(&account{
+		Name:     "tom",
+		Password: "123456",
+		Idcard:   "110101199001011234",
+		Ignored:  "ignored",
+		Created:  created,
+		Cards:    []card{{No: "6222021234567891233"}},
+		internal: "internal",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"name":     "tom",
+		"password": Mask,
+		"idcard":   Mask,
+		"created":  created,
+		"cards":    []interface{}{map[string]interface{}{"no": "622************1233"}},
+	}, v)
+
+	assert.Equal(t, map[string]interface{}{"Access-Token": Mask, "id": 1}, Value(map[string]interface{}{"Access-Token": "abc", "id": 1}))
+	assert.Nil(t, Value(nil))
+	assert.Equal(t, 1, Value(1))
+}
+
+type node struct {
+	Name   string  `json:"name"`
+	Token  string  `json:"token"`
+	Parent *node   `json:"parent"`
+	Next   []*node `json:"next"`
+}
+
+func TestValue_Cycle(t *testing.T) {
+	root := &node{Name: "root", Token: "abc"}
+	child := &node{Name: "child", Parent: root}
+	root.Next = []*node{child, child}
+
+	assert.Equal(t, map[string]interface{}{
+		"name":   "root",
+		"token":  Mask,
+		"parent": nil,
+		"next": []interface{}{
+			map[string]interface{}{"name": "child", "token": Mask, "parent": Truncated, "next": nil},
+			map[string]interface{}{"name": "child", "token": Mask, "parent": Truncated, "next": nil},
+		},
+	}, Value(root))
+
+	m := map[string]interface{}{"name": "m"}
+	m["self"] = m
+	assert.Equal(t, map[string]interface{}{"name": "m", "self": Truncated}, Value(m))
+
+	var deep interface{} = "leaf"
+	for i := 0; i < MaxDepth*2; i++ {
+		deep = []interface{}{deep}
+	}
+	assert.NotPanics(t, func() { Value(deep) })
+}
+
+func TestJSON(t *testing.T) {
+	assert.JSONEq(t, `{"user":{"name":"tom","pwd":"******"},"id":12345,"list":["622************1233"]}`,
+		string(JSON([]byte(`{"user":{"name":"tom","pwd":"123"},"id":12345,"list":["6222021234567891233"]}`))))
+	assert.Equal(t, "bad 622************1233", string(JSON([]byte("bad 6222021234567891233"))))
+	// 数值保持原样，不被改写为字符串
+	assert.JSONEq(t, `{"id":11010519491231002,"card":6222021234567891233,"no":"622************1233"}`,
+		string(JSON([]byte(`{"id":11010519491231002,"card":6222021234567891233,"no":"6222021234567891233"}`))))
+}
+
+func TestJSONFragment(t *testing.T) {
+	assert.Equal(t, `{"name":"tom","password": "******","list":[{"token":"******"`,
+		JSONFragment(`{"name":"tom","password": "123\"456","list":[{"token":"abc`))
+	assert.Equal(t, `"pwd":"******","id":6222021234567891233,"card":"622************1233"}`,
+		JSONFragment(`"pwd":123456,"id":6222021234567891233,"card":"6222021234567891233"}`))
+	assert.Equal(t, `{"name":"tom","password":"******"`, string(JSON([]byte(`{"name":"tom","password":"123"`))))
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT * FROM `users` WHERE `users`.`password` = 'abc' AND name = 'tom'",
+			"SELECT * FROM `users` WHERE `users`.`password` = '******' AND name = 'tom'",
+		},
+		{
+			"UPDATE `users` SET `secret_key`='a\\'b',`updated_at`='2022-01-01' WHERE id = 1",
+			"UPDATE `users` SET `secret_key`='******',`updated_at`='2022-01-01' WHERE id = 1",
+		},
+		{
+			"SELECT * FROM users WHERE token IN ('a','b') AND pwd LIKE \"x%\"",
+			"SELECT * FROM users WHERE token IN ('******') AND pwd LIKE '******'",
+		},
+		{
+			"INSERT INTO `users` (`name`,`password`,`created_at`) VALUES ('tom','p,w''d',NOW()),('jerry',123,NOW()) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)",
+			"INSERT INTO `users` (`name`,`password`,`created_at`) VALUES ('tom','******',NOW()),('jerry','******',NOW()) ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)",
+		},
+		{
+			"INSERT INTO `users` (`name`) VALUES ('tom')",
+			"INSERT INTO `users` (`name`) VALUES ('tom')",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SQL(tt.sql))
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := New([]string{"mobile"}, []string{"mobile"})
+	assert.True(t, r.IsSensitiveKey("Mobile"))
+	assert.False(t, r.IsSensitiveKey("password"))
+	assert.Equal(t, "6222021234567891233", r.String("6222021234567891233"))
+	assert.Equal(t, "SELECT * FROM users WHERE mobile = '******'", r.SQL("SELECT * FROM users WHERE mobile = 13800000000"))
+
+	r.AddRules(NewRule("mobile", `\b1[3-9][0-9]{9}\b`, nil))
+	assert.Equal(t, "call 138****0000", r.String("call 13800000000"))
+
+	r.SetRules()
+	assert.Equal(t, "call 13800000000", r.String("call 13800000000"))
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// LevelPanic slog的panic日志级别
+const LevelPanic = slog.LevelError + 4
+
+// FromSlog 将slog日志记录器适配为日志记录器，l为空时使用slog.Default()
+func FromSlog(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return &slogLogger{l: l}
+}
+
+// slogLogger 基于slog.Logger实现的日志记录器
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// Debugf debug
+func (s *slogLogger) Debugf(format string, data ...interface{}) {
+	s.logf(slog.LevelDebug, format, data...)
+}
+
+// Infof info
+func (s *slogLogger) Infof(format string, data ...interface{}) {
+	s.logf(slog.LevelInfo, format, data...)
+}
+
+// Warnf warn
+func (s *slogLogger) Warnf(format string, data ...interface{}) {
+	s.logf(slog.LevelWarn, format, data...)
+}
+
+// Errorf error
+func (s *slogLogger) Errorf(format string, data ...interface{}) {
+	s.logf(slog.LevelError, format, data...)
+}
+
+// Panicf panic，记录日志后panic
+func (s *slogLogger) Panicf(format string, data ...interface{}) {
+	msg := fmt.Sprintf(format, data...)
+	s.log(LevelPanic, msg)
+	panic(msg)
+}
+
+// Debugw debug，附带键值对字段
+func (s *slogLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	s.log(slog.LevelDebug, msg, keysAndValues...)
+}
+
+// Infow info，附带键值对字段
+func (s *slogLogger) Infow(msg string, keysAndValues ...interface{}) {
+	s.log(slog.LevelInfo, msg, keysAndValues...)
+}
+
+// Warnw warn，附带键值对字段
+func (s *slogLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	s.log(slog.LevelWarn, msg, keysAndValues...)
+}
+
+// Errorw error，附带键值对字段
+func (s *slogLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	s.log(slog.LevelError, msg, keysAndValues...)
+}
+
+// Panicw panic，附带键值对字段，记录日志后panic
+func (s *slogLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	s.log(LevelPanic, msg, keysAndValues...)
+	panic(msg)
+}
+
+// With 返回附带键值对字段的日志记录器
+func (s *slogLogger) With(keysAndValues ...interface{}) Logger {
+	return &slogLogger{l: s.l.With(keysAndValues...)}
+}
+
+// logf 格式化消息后记录日志
+func (s *slogLogger) logf(level slog.Level, format string, data ...interface{}) {
+	if !s.l.Enabled(context.Background(), level) {
+		return
+	}
+
+	s.write(level, fmt.Sprintf(format, data...))
+}
+
+// log 记录附带键值对字段的日志
+func (s *slogLogger) log(level slog.Level, msg string, keysAndValues ...interface{}) {
+	if !s.l.Enabled(context.Background(), level) {
+		return
+	}
+
+	s.write(level, msg, keysAndValues...)
+}
+
+// write 写入日志，调用位置为日志记录器方法的调用方
+func (s *slogLogger) write(level slog.Level, msg string, keysAndValues ...interface{}) {
+	var pcs [1]uintptr
+	// 跳过runtime.Callers、write、log/logf和日志记录器方法
+	runtime.Callers(4, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(keysAndValues...)
+	_ = s.l.Handler().Handle(context.Background(), r)
+}
@@ -0,0 +1,164 @@
+package xzap
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+)
+
+const (
+	// PolicyBlock 缓冲区已满时阻塞等待
+	PolicyBlock = "block"
+	// PolicyDrop 缓冲区已满时丢弃日志
+	PolicyDrop = "drop"
+
+	// DefaultBufferSize 默认异步缓冲区大小（日志条数）
+	DefaultBufferSize = 1024
+)
+
+// dropped 全部异步写入器丢弃的日志条数
+var dropped atomic.Uint64
+
+// Dropped 获取全部异步写入器丢弃的日志条数
+func Dropped() uint64 {
+	return dropped.Load()
+}
+
+// AsyncWriter 异步日志写入器，日志先写入有界缓冲区，由后台协程写入底层写入器
+type AsyncWriter struct {
+	ws      zapcore.WriteSyncer
+	buf     chan []byte
+	flush   chan chan error
+	done    chan struct{}
+	drop    bool
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncWriter 新建异步日志写入器
+func NewAsyncWriter(ws zapcore.WriteSyncer, c logging.AsyncConf) *AsyncWriter {
+	size := c.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	w := &AsyncWriter{
+		ws:    ws,
+		buf:   make(chan []byte, size),
+		flush: make(chan chan error),
+		done:  make(chan struct{}),
+		drop:  c.Policy == PolicyDrop,
+	}
+	go w.run()
+
+	return w
+}
+
+// Write 写入日志到缓冲区，关闭后直接写入底层写入器
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return w.ws.Write(p)
+	}
+
+	// zap会复用p，写入缓冲区前需要复制
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	if !w.drop {
+		w.buf <- b
+		return len(p), nil
+	}
+
+	select {
+	case w.buf <- b:
+	default:
+		w.dropped.Add(1)
+		dropped.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// Sync 将缓冲区中的日志全部写入底层写入器并刷新
+func (w *AsyncWriter) Sync() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return w.ws.Sync()
+	}
+
+	ch := make(chan error, 1)
+	w.flush <- ch
+	w.mu.RUnlock()
+
+	return <-ch
+}
+
+// Close 将缓冲区中的日志全部写入底层写入器后关闭，底层写入器实现了io.Closer时一并关闭
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.buf)
+	w.mu.Unlock()
+
+	<-w.done
+	err := w.ws.Sync()
+	if c, ok := w.ws.(io.Closer); ok {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Dropped 获取丢弃的日志条数
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// run 后台协程将缓冲区中的日志写入底层写入器
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case b, ok := <-w.buf:
+			if !ok {
+				return
+			}
+			_, _ = w.ws.Write(b)
+		case ch := <-w.flush:
+			w.drain()
+			ch <- w.ws.Sync()
+		}
+	}
+}
+
+// drain 将缓冲区中已有的日志全部写入底层写入器
+func (w *AsyncWriter) drain() {
+	for {
+		select {
+		case b, ok := <-w.buf:
+			if !ok {
+				return
+			}
+			_, _ = w.ws.Write(b)
+		default:
+			return
+		}
+	}
+}
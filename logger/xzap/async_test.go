@@ -0,0 +1,100 @@
+package xzap
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+)
+
+// blockingWriter 写入时等待放行的写入器
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+	synced  int
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.synced++
+	return nil
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{})}
+	close(bw.release)
+
+	w := NewAsyncWriter(bw, logging.AsyncConf{Enabled: true})
+	p := []byte("hello\n")
+	_, err := w.Write(p)
+	require.NoError(t, err)
+	copy(p, "world\n")
+	_, err = w.Write(p)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Sync())
+	assert.Equal(t, "hello\nworld\n", bw.String())
+	assert.Equal(t, 1, bw.synced)
+
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	_, err = w.Write([]byte("closed\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\nworld\nclosed\n", bw.String())
+}
+
+func TestAsyncWriter_Drop(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(bw, logging.AsyncConf{Enabled: true, BufferSize: 2, Policy: PolicyDrop})
+
+	before := Dropped()
+	// 后台协程阻塞在第1条日志的写入上，缓冲区最多容纳2条
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte("log\n"))
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, w.Dropped(), uint64(7))
+	assert.Equal(t, w.Dropped(), Dropped()-before)
+
+	close(bw.release)
+	require.NoError(t, w.Close())
+	assert.Equal(t, 10-int(w.Dropped()), bytes.Count([]byte(bw.String()), []byte("log\n")))
+}
+
+func TestInit_Async(t *testing.T) {
+	defer ReplaceLogger(L())()
+	defer levels.set(RootModule, levelPtr(zapcore.InfoLevel), 0)
+
+	dir := t.TempDir()
+	require.NoError(t, Init(logging.LogConf{Mode: ModeFile, Path: dir, Async: logging.AsyncConf{Enabled: true}}))
+	for i := 0; i < 100; i++ {
+		L().Info("async test")
+	}
+	require.NoError(t, Close())
+
+	access, err := os.ReadFile(filepath.Join(dir, accessFilename))
+	require.NoError(t, err)
+	assert.Equal(t, 100, bytes.Count(access, []byte("async test")))
+}
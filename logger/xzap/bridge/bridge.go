@@ -0,0 +1,42 @@
+package bridge
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"cxqi/common/logger/xzap"
+)
+
+// InstallAll 将gRPC、go-zero和标准库log的日志统一转发到xzap，需在程序启动时xzap.Init之后执行，
+// 返回恢复标准库log原设置的函数。gorm日志由gdb.NewDB转发，直接使用gorm.Open时可通过gdb.NewLogger设置
+func InstallAll() func() {
+	InstallGRPCLog()
+	InstallLogx()
+	return RedirectStdLog()
+}
+
+// moduleLogger 基于模块日志记录器派生的日志记录器缓存，模块日志记录器重建后重新派生
+type moduleLogger struct {
+	module string
+	derive func(l *zap.Logger, key int) *zap.Logger
+	cache  sync.Map // key -> *derivedLogger
+}
+
+// derivedLogger 派生的日志记录器
+type derivedLogger struct {
+	from   *zap.Logger
+	logger *zap.Logger
+}
+
+// get 获取key对应的派生日志记录器
+func (m *moduleLogger) get(key int) *zap.Logger {
+	from := xzap.Module(m.module)
+	if v, ok := m.cache.Load(key); ok && v.(*derivedLogger).from == from {
+		return v.(*derivedLogger).logger
+	}
+
+	l := m.derive(from, key)
+	m.cache.Store(key, &derivedLogger{from: from, logger: l})
+	return l
+}
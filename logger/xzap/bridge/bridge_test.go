@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/grpclog"
+
+	"cxqi/common/logger/xzap"
+	"cxqi/common/logger/xzap/xzaptest"
+)
+
+func TestGRPCLogger(t *testing.T) {
+	logs := xzaptest.Replace(t)
+	l := NewGRPCLogger()
+
+	l.Infof("info %d", 1)
+	l.Warningln("warn", 1)
+	l.Error("error")
+	assert.True(t, l.V(0))
+	assert.False(t, l.V(2))
+
+	assert.Equal(t, []string{"info 1", "warn 1", "error"}, logs.Messages())
+	logs.AssertLogged(t, zapcore.WarnLevel, "warn 1")
+
+	// gRPC模块日志级别可在运行时调整
+	assert.NoError(t, xzap.SetLevel(GRPCModule, "error"))
+	defer xzap.ResetLevel(GRPCModule)
+	var _ grpclog.LoggerV2 = l
+	l.Info("ignored")
+	logs.AssertLen(t, 3)
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	logs := xzaptest.Replace(t)
+	defer RedirectStdLog(zapcore.WarnLevel)()
+
+	log.Printf("std %s", "log")
+	logs.AssertLogged(t, zapcore.WarnLevel, "std log")
+
+	NewStdLog().Println("new std log")
+	logs.AssertLogged(t, zapcore.InfoLevel, "new std log")
+}
+
+func TestLogxWriter(t *testing.T) {
+	logs := xzaptest.Replace(t)
+	w := NewLogxWriter()
+
+	w.Info("info", logx.LogField{Key: "k", Value: "v"})
+	w.Slow("slow")
+	w.Severe("severe")
+
+	assert.Equal(t, 1, logs.FilterField("k", "v").Len())
+	assert.Equal(t, 1, logs.FilterLogger(xzap.SlowLoggerName).Len())
+	logs.AssertLogged(t, zapcore.ErrorLevel, "severe")
+}
+
+func TestInstallAll(t *testing.T) {
+	logs := xzaptest.Replace(t)
+	restore := InstallAll()
+
+	log.Print("std log")
+	logs.AssertLogged(t, zapcore.InfoLevel, "std log")
+
+	restore()
+	log.Print("restored")
+	logs.AssertLen(t, 1)
+}
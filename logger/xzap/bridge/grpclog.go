@@ -0,0 +1,138 @@
+package bridge
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/grpclog"
+
+	"cxqi/common/logger/xzap"
+)
+
+// GRPCModule gRPC内部日志的模块名称，可通过xzap.SetLevel在运行时调整日志级别
+const GRPCModule = "grpc"
+
+// grpcLogger gRPC内部日志的模块日志记录器，按跳过的调用层数派生
+var grpcLogger = &moduleLogger{module: GRPCModule, derive: func(l *zap.Logger, skip int) *zap.Logger {
+	return l.WithOptions(zap.AddCallerSkip(skip))
+}}
+
+// GRPCLogger 转发到xzap的grpclog.LoggerV2
+type GRPCLogger struct {
+	verbosity int
+}
+
+var _ grpclog.DepthLoggerV2 = (*GRPCLogger)(nil)
+
+// NewGRPCLogger 新建转发到xzap的grpclog.LoggerV2，verbosity为V方法启用的最大详细级别
+func NewGRPCLogger(verbosity ...int) *GRPCLogger {
+	xzap.RegisterModule(GRPCModule)
+
+	l := &GRPCLogger{}
+	if len(verbosity) > 0 {
+		l.verbosity = verbosity[0]
+	}
+
+	return l
+}
+
+// InstallGRPCLog 将gRPC内部日志转发到xzap，需在调用gRPC的任何方法前执行
+func InstallGRPCLog(verbosity ...int) {
+	grpclog.SetLoggerV2(NewGRPCLogger(verbosity...))
+}
+
+// Info info
+func (l *GRPCLogger) Info(args ...interface{}) {
+	l.logger(1).Info(fmt.Sprint(args...))
+}
+
+// Infoln info
+func (l *GRPCLogger) Infoln(args ...interface{}) {
+	l.logger(1).Info(sprintln(args...))
+}
+
+// Infof info
+func (l *GRPCLogger) Infof(format string, args ...interface{}) {
+	l.logger(1).Info(fmt.Sprintf(format, args...))
+}
+
+// InfoDepth info，depth为跳过的调用层数
+func (l *GRPCLogger) InfoDepth(depth int, args ...interface{}) {
+	l.logger(depth + 1).Info(sprintln(args...))
+}
+
+// Warning warn
+func (l *GRPCLogger) Warning(args ...interface{}) {
+	l.logger(1).Warn(fmt.Sprint(args...))
+}
+
+// Warningln warn
+func (l *GRPCLogger) Warningln(args ...interface{}) {
+	l.logger(1).Warn(sprintln(args...))
+}
+
+// Warningf warn
+func (l *GRPCLogger) Warningf(format string, args ...interface{}) {
+	l.logger(1).Warn(fmt.Sprintf(format, args...))
+}
+
+// WarningDepth warn，depth为跳过的调用层数
+func (l *GRPCLogger) WarningDepth(depth int, args ...interface{}) {
+	l.logger(depth + 1).Warn(sprintln(args...))
+}
+
+// Error error
+func (l *GRPCLogger) Error(args ...interface{}) {
+	l.logger(1).Error(fmt.Sprint(args...))
+}
+
+// Errorln error
+func (l *GRPCLogger) Errorln(args ...interface{}) {
+	l.logger(1).Error(sprintln(args...))
+}
+
+// Errorf error
+func (l *GRPCLogger) Errorf(format string, args ...interface{}) {
+	l.logger(1).Error(fmt.Sprintf(format, args...))
+}
+
+// ErrorDepth error，depth为跳过的调用层数
+func (l *GRPCLogger) ErrorDepth(depth int, args ...interface{}) {
+	l.logger(depth + 1).Error(sprintln(args...))
+}
+
+// Fatal fatal，记录日志后退出程序
+func (l *GRPCLogger) Fatal(args ...interface{}) {
+	l.logger(1).Fatal(fmt.Sprint(args...))
+}
+
+// Fatalln fatal，记录日志后退出程序
+func (l *GRPCLogger) Fatalln(args ...interface{}) {
+	l.logger(1).Fatal(sprintln(args...))
+}
+
+// Fatalf fatal，记录日志后退出程序
+func (l *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	l.logger(1).Fatal(fmt.Sprintf(format, args...))
+}
+
+// FatalDepth fatal，depth为跳过的调用层数，记录日志后退出程序
+func (l *GRPCLogger) FatalDepth(depth int, args ...interface{}) {
+	l.logger(depth + 1).Fatal(sprintln(args...))
+}
+
+// V 判断详细级别是否启用
+func (l *GRPCLogger) V(level int) bool {
+	return level <= l.verbosity
+}
+
+// logger 获取gRPC模块的zap日志记录器，skip为跳过的调用层数
+func (l *GRPCLogger) logger(skip int) *zap.Logger {
+	return grpcLogger.get(skip)
+}
+
+// sprintln 拼接参数并去除末尾的换行符
+func sprintln(args ...interface{}) string {
+	s := fmt.Sprintln(args...)
+	return s[:len(s)-1]
+}
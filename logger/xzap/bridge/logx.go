@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.uber.org/zap"
+
+	"cxqi/common/logger/xzap"
+)
+
+const (
+	// LogxModule go-zero日志的模块名称
+	LogxModule = "logx"
+
+	// logxStatLoggerName go-zero统计日志的日志记录器名称
+	logxStatLoggerName = "stat"
+)
+
+// logxLogger logx日志的模块日志记录器，logx已在字段中记录调用位置，不再重复记录
+var logxLogger = &moduleLogger{module: LogxModule, derive: func(l *zap.Logger, _ int) *zap.Logger {
+	return l.WithOptions(zap.WithCaller(false))
+}}
+
+// LogxWriter 转发到xzap的go-zero logx.Writer
+type LogxWriter struct{}
+
+var _ logx.Writer = (*LogxWriter)(nil)
+
+// NewLogxWriter 新建转发到xzap的go-zero logx.Writer
+func NewLogxWriter() *LogxWriter {
+	xzap.RegisterModule(LogxModule)
+	return &LogxWriter{}
+}
+
+// InstallLogx 将go-zero日志转发到xzap，logx不再过滤日志级别，由xzap模块日志级别决定
+func InstallLogx() {
+	logx.SetWriter(NewLogxWriter())
+	logx.SetLevel(logx.DebugLevel)
+}
+
+// Alert alert，以error级别记录
+func (w *LogxWriter) Alert(v interface{}) {
+	w.logger().Error(toString(v), zap.Bool("alert", true))
+}
+
+// Close 刷新缓冲区
+func (w *LogxWriter) Close() error {
+	return w.logger().Sync()
+}
+
+// Debug debug
+func (w *LogxWriter) Debug(v interface{}, fields ...logx.LogField) {
+	w.logger().Debug(toString(v), toFields(fields)...)
+}
+
+// Error error
+func (w *LogxWriter) Error(v interface{}, fields ...logx.LogField) {
+	w.logger().Error(toString(v), toFields(fields)...)
+}
+
+// Info info
+func (w *LogxWriter) Info(v interface{}, fields ...logx.LogField) {
+	w.logger().Info(toString(v), toFields(fields)...)
+}
+
+// Severe severe，以error级别记录
+func (w *LogxWriter) Severe(v interface{}) {
+	w.logger().Error(toString(v), zap.Bool("severe", true))
+}
+
+// Slow slow，写入慢日志
+func (w *LogxWriter) Slow(v interface{}, fields ...logx.LogField) {
+	w.logger().Named(xzap.SlowLoggerName).Warn(toString(v), toFields(fields)...)
+}
+
+// Stack stack，以error级别记录
+func (w *LogxWriter) Stack(v interface{}) {
+	w.logger().Error(toString(v))
+}
+
+// Stat stat，以info级别记录
+func (w *LogxWriter) Stat(v interface{}, fields ...logx.LogField) {
+	w.logger().Named(logxStatLoggerName).Info(toString(v), toFields(fields)...)
+}
+
+// logger 获取logx模块的zap日志记录器
+func (w *LogxWriter) logger() *zap.Logger {
+	return logxLogger.get(0)
+}
+
+// toString 将日志内容转换为字符串
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case error:
+		return s.Error()
+	case fmt.Stringer:
+		return s.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toFields 将logx字段转换为zap字段
+func toFields(fields []logx.LogField) []zap.Field {
+	zfs := make([]zap.Field, 0, len(fields))
+	for _, f := range fields {
+		zfs = append(zfs, zap.Any(f.Key, f.Value))
+	}
+
+	return zfs
+}
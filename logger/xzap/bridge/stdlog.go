@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"bytes"
+	"log"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"cxqi/common/logger/xzap"
+)
+
+// StdLogModule 标准库log日志的模块名称
+const StdLogModule = "stdlog"
+
+// stdLogCallerSkip 跳过stdWriter.Write、log.(*Logger).output和log.Printf等调用层
+const stdLogCallerSkip = 3
+
+// stdLogger 标准库log日志的模块日志记录器
+var stdLogger = &moduleLogger{module: StdLogModule, derive: func(l *zap.Logger, _ int) *zap.Logger {
+	return l.WithOptions(zap.AddCallerSkip(stdLogCallerSkip))
+}}
+
+// stdWriter 转发到xzap的标准库log输出
+type stdWriter struct {
+	level zapcore.Level
+}
+
+// Write 将一行标准库log日志写入xzap
+func (w *stdWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, "\n"))
+	if ce := stdLogger.get(0).Check(w.level, msg); ce != nil {
+		ce.Write()
+	}
+
+	return len(p), nil
+}
+
+// NewStdLog 新建转发到xzap的标准库log日志记录器，level为空时使用info级别
+func NewStdLog(level ...zapcore.Level) *log.Logger {
+	xzap.RegisterModule(StdLogModule)
+	return log.New(newStdWriter(level...), "", 0)
+}
+
+// RedirectStdLog 将标准库log的全局日志记录器转发到xzap，返回恢复原设置的函数
+func RedirectStdLog(level ...zapcore.Level) func() {
+	xzap.RegisterModule(StdLogModule)
+
+	flags, prefix, out := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(newStdWriter(level...))
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}
+}
+
+// newStdWriter 新建转发到xzap的标准库log输出
+func newStdWriter(level ...zapcore.Level) *stdWriter {
+	w := &stdWriter{level: zapcore.InfoLevel}
+	if len(level) > 0 {
+		w.level = level[0]
+	}
+
+	return w
+}
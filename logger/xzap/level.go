@@ -0,0 +1,352 @@
+package xzap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+)
+
+const (
+	// RootModule 根模块名称，未覆盖级别的模块使用根模块的日志级别
+	RootModule = "root"
+)
+
+// levels 全局日志级别注册表
+var levels = newLevelRegistry()
+
+// levelRegistry 日志级别注册表，支持按模块覆盖根模块的日志级别
+type levelRegistry struct {
+	mu      sync.RWMutex
+	root    zapcore.Level
+	modules map[string]*moduleLevel
+	min     atomic.Int32
+}
+
+// moduleLevel 模块日志级别
+type moduleLevel struct {
+	level *zapcore.Level // 为nil时使用根模块的日志级别
+	gen   uint64         // 级别变更代数，用于过期恢复时判断级别是否已被再次修改
+	timer *time.Timer
+}
+
+// newLevelRegistry 新建日志级别注册表
+func newLevelRegistry() *levelRegistry {
+	r := &levelRegistry{root: zapcore.InfoLevel, modules: make(map[string]*moduleLevel)}
+	r.min.Store(int32(r.root))
+	return r
+}
+
+// Enabled 判断日志级别对任一模块是否启用，实现zapcore.LevelEnabler接口
+func (r *levelRegistry) Enabled(l zapcore.Level) bool {
+	return int32(l) >= r.min.Load()
+}
+
+// enabled 判断日志级别对指定模块是否启用
+func (r *levelRegistry) enabled(module string, l zapcore.Level) bool {
+	return l >= r.level(module)
+}
+
+// level 获取指定模块的生效日志级别
+func (r *levelRegistry) level(module string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if m, ok := r.modules[module]; ok && m.level != nil {
+		return *m.level
+	}
+
+	return r.root
+}
+
+// override 获取指定模块覆盖的日志级别
+func (r *levelRegistry) override(module string) (zapcore.Level, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if m, ok := r.modules[module]; ok && m.level != nil {
+		return *m.level, true
+	}
+
+	return 0, false
+}
+
+// register 注册模块
+func (r *levelRegistry) register(module string) {
+	if isRoot(module) {
+		return
+	}
+
+	r.mu.RLock()
+	_, ok := r.modules[module]
+	r.mu.RUnlock()
+	if ok {
+		return
+	}
+
+	r.mu.Lock()
+	if _, ok := r.modules[module]; !ok {
+		r.modules[module] = &moduleLevel{}
+	}
+	r.mu.Unlock()
+}
+
+// set 设置模块日志级别，level为nil时取消模块的级别覆盖，ttl大于0时过期后恢复原级别
+func (r *levelRegistry) set(module string, level *zapcore.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.module(module)
+	prev := m.level
+	if isRoot(module) {
+		p := r.root
+		prev = &p
+		r.root = *level
+	} else {
+		m.level = level
+	}
+
+	m.gen++
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	if ttl > 0 {
+		gen := m.gen
+		m.timer = time.AfterFunc(ttl, func() {
+			r.revert(module, gen, prev)
+		})
+	}
+
+	r.updateMin()
+}
+
+// revert 过期后恢复模块的原日志级别，级别已被再次修改时不做处理
+func (r *levelRegistry) revert(module string, gen uint64, prev *zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.module(module)
+	if m.gen != gen {
+		return
+	}
+
+	if isRoot(module) {
+		r.root = *prev
+	} else {
+		m.level = prev
+	}
+	m.timer = nil
+	r.updateMin()
+}
+
+// module 获取模块，不存在时注册，调用方需持有写锁
+func (r *levelRegistry) module(module string) *moduleLevel {
+	if isRoot(module) {
+		module = RootModule
+	}
+
+	m, ok := r.modules[module]
+	if !ok {
+		m = &moduleLevel{}
+		r.modules[module] = m
+	}
+
+	return m
+}
+
+// updateMin 更新全部模块中的最低日志级别，调用方需持有写锁
+func (r *levelRegistry) updateMin() {
+	min := r.root
+	for _, m := range r.modules {
+		if m.level != nil && *m.level < min {
+			min = *m.level
+		}
+	}
+	r.min.Store(int32(min))
+}
+
+// all 获取全部模块的生效日志级别
+func (r *levelRegistry) all() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := map[string]string{RootModule: r.root.String()}
+	for name, m := range r.modules {
+		if isRoot(name) {
+			continue
+		}
+		if m.level != nil {
+			all[name] = m.level.String()
+		} else {
+			all[name] = r.root.String()
+		}
+	}
+
+	return all
+}
+
+// isRoot 判断是否为根模块
+func isRoot(module string) bool {
+	return module == "" || module == RootModule
+}
+
+// moduleCore 按模块日志级别过滤日志的zap core
+type moduleCore struct {
+	zapcore.Core
+	module string
+}
+
+// Enabled 判断日志级别是否启用
+func (c *moduleCore) Enabled(l zapcore.Level) bool {
+	return levels.enabled(c.module, l) && c.Core.Enabled(l)
+}
+
+// With 添加字段
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{Core: c.Core.With(fields), module: c.module}
+}
+
+// Check 判断日志条目是否需要记录
+func (c *moduleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !levels.enabled(c.module, ent.Level) {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// RegisterModule 注册模块，注册后的模块会出现在日志级别列表中
+func RegisterModule(module string) {
+	levels.register(module)
+}
+
+// SetLevel 设置模块日志级别，模块为空时设置根模块，ttl大于0时过期后恢复原级别
+func SetLevel(module, level string, ttl ...time.Duration) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	levels.set(module, &l, getTTL(ttl...))
+	return nil
+}
+
+// ResetLevel 取消模块的日志级别覆盖，使其使用根模块的日志级别
+func ResetLevel(module string, ttl ...time.Duration) {
+	if isRoot(module) {
+		return
+	}
+
+	levels.set(module, nil, getTTL(ttl...))
+}
+
+// ChangeLevel 按字符串参数修改模块日志级别，level为空时取消模块的级别覆盖，
+// ttl为时间间隔字符串（如10m），为空时不过期，用于日志级别管理接口
+func ChangeLevel(module, level, ttl string) error {
+	var d time.Duration
+	if ttl != "" {
+		var err error
+		if d, err = time.ParseDuration(ttl); err != nil || d < 0 {
+			return errors.Errorf("xzap: illegal level ttl: %s", ttl)
+		}
+	}
+
+	if level == "" {
+		if isRoot(module) {
+			return errors.New("xzap: root module level can not be reset")
+		}
+		ResetLevel(module, d)
+		return nil
+	}
+
+	return SetLevel(module, level, d)
+}
+
+// GetLevel 获取模块的生效日志级别，模块为空时获取根模块的日志级别
+func GetLevel(module string) string {
+	if isRoot(module) {
+		module = RootModule
+	}
+
+	return levels.level(module).String()
+}
+
+// ModuleLevel 获取模块覆盖的日志级别，未覆盖时返回false
+func ModuleLevel(module string) (zapcore.Level, bool) {
+	return levels.override(module)
+}
+
+// Levels 获取全部模块的生效日志级别
+func Levels() map[string]string {
+	return levels.all()
+}
+
+// Modules 获取全部已注册的模块名称
+func Modules() []string {
+	all := levels.all()
+	modules := make([]string, 0, len(all))
+	for name := range all {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+
+	return modules
+}
+
+// moduleLoggers 模块日志记录器缓存，module -> *moduleLogger
+var moduleLoggers sync.Map
+
+// moduleLogger 基于全局基础日志记录器构建的模块日志记录器
+type moduleLogger struct {
+	base   *zap.Logger
+	logger *zap.Logger
+}
+
+// Module 获取指定模块的zap日志记录器，日志按模块的生效日志级别过滤，
+// 日志记录器按模块缓存，全局基础日志记录器替换后重新构建
+func Module(module string) *zap.Logger {
+	l := L()
+	if v, ok := moduleLoggers.Load(module); ok && v.(*moduleLogger).base == l {
+		return v.(*moduleLogger).logger
+	}
+
+	levels.register(module)
+	ml := &moduleLogger{base: l, logger: l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		if mc, ok := c.(*moduleCore); ok {
+			c = mc.Core
+		}
+		return &moduleCore{Core: c, module: module}
+	}))}
+	moduleLoggers.Store(module, ml)
+
+	return ml.logger
+}
+
+// ModuleWithContext 获取指定模块携带上下文字段（链路追踪id和请求tags）的日志记录器
+func ModuleWithContext(ctx context.Context, module string) logging.Logger {
+	l := Module(module)
+	if ctx != nil {
+		if fields := ContextFields(ctx); len(fields) > 0 {
+			l = l.With(fields...)
+		}
+	}
+
+	return Wrap(l)
+}
+
+// getTTL 获取可选的过期时间
+func getTTL(ttl ...time.Duration) time.Duration {
+	if len(ttl) > 0 {
+		return ttl[0]
+	}
+
+	return 0
+}
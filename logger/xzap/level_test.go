@@ -0,0 +1,94 @@
+package xzap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer ReplaceLogger(zap.New(&moduleCore{Core: core, module: RootModule}))()
+	defer levels.set(RootModule, levelPtr(zapcore.InfoLevel), 0)
+
+	require.NoError(t, SetLevel("", "warn"))
+	assert.Equal(t, "warn", GetLevel(RootModule))
+
+	WithContext(context.Background()).Infof("root info")
+	ModuleWithContext(context.Background(), "test_module").Infof("module info")
+	assert.Equal(t, 0, logs.Len())
+
+	require.NoError(t, SetLevel("test_module", "debug"))
+	WithContext(context.Background()).Infof("root info")
+	ModuleWithContext(context.Background(), "test_module").Debugf("module debug")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "module debug", logs.All()[0].Message)
+
+	l, ok := ModuleLevel("test_module")
+	assert.True(t, ok)
+	assert.Equal(t, zapcore.DebugLevel, l)
+	assert.Equal(t, map[string]string{RootModule: "warn", "test_module": "debug"}, filterLevels(Levels(), "test_module"))
+
+	ResetLevel("test_module")
+	_, ok = ModuleLevel("test_module")
+	assert.False(t, ok)
+	assert.Equal(t, "warn", GetLevel("test_module"))
+
+	assert.EqualError(t, SetLevel("", "unknown"), "xzap: illegal log level: unknown")
+}
+
+func TestModule_Cached(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer ReplaceLogger(zap.New(core))()
+
+	l := Module("cached_module")
+	assert.Same(t, l, Module("cached_module"))
+
+	core2, logs2 := observer.New(zapcore.DebugLevel)
+	defer ReplaceLogger(zap.New(core2))()
+
+	assert.NotSame(t, l, Module("cached_module"))
+	Module("cached_module").Info("after replace")
+	assert.Equal(t, 0, logs.Len())
+	assert.Equal(t, 1, logs2.Len())
+}
+
+func TestChangeLevel_TTL(t *testing.T) {
+	defer ResetLevel("ttl_module")
+
+	require.NoError(t, ChangeLevel("ttl_module", "debug", "50ms"))
+	assert.Equal(t, "debug", GetLevel("ttl_module"))
+
+	assert.Eventually(t, func() bool {
+		_, ok := ModuleLevel("ttl_module")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	// 再次修改后，之前的过期恢复不再生效
+	require.NoError(t, ChangeLevel("ttl_module", "error", "50ms"))
+	require.NoError(t, ChangeLevel("ttl_module", "warn", ""))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "warn", GetLevel("ttl_module"))
+
+	assert.EqualError(t, ChangeLevel("ttl_module", "debug", "abc"), "xzap: illegal level ttl: abc")
+	assert.EqualError(t, ChangeLevel("", "", ""), "xzap: root module level can not be reset")
+}
+
+func levelPtr(l zapcore.Level) *zapcore.Level {
+	return &l
+}
+
+func filterLevels(all map[string]string, modules ...string) map[string]string {
+	filtered := map[string]string{RootModule: all[RootModule]}
+	for _, m := range modules {
+		filtered[m] = all[m]
+	}
+
+	return filtered
+}
@@ -0,0 +1,62 @@
+package xzap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"cxqi/common/logger/redact"
+)
+
+// redactEncoder 脱敏日志消息和字段的编码器
+type redactEncoder struct {
+	zapcore.Encoder
+}
+
+// Clone 复制编码器
+func (e *redactEncoder) Clone() zapcore.Encoder {
+	return &redactEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry 脱敏后编码日志
+func (e *redactEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	ent.Message = redact.String(ent.Message)
+	return e.Encoder.EncodeEntry(ent, redactFields(fields))
+}
+
+// AddString 脱敏后编码字符串字段，用于With附带的字段
+func (e *redactEncoder) AddString(key, value string) {
+	if redact.IsSensitiveKey(key) {
+		value = redact.Mask
+	}
+	e.Encoder.AddString(key, redact.String(value))
+}
+
+// AddReflected 脱敏后编码反射字段，用于With附带的字段
+func (e *redactEncoder) AddReflected(key string, value interface{}) error {
+	if redact.IsSensitiveKey(key) {
+		e.Encoder.AddString(key, redact.Mask)
+		return nil
+	}
+
+	return e.Encoder.AddReflected(key, redact.Value(value))
+}
+
+// redactFields 脱敏字段，敏感字段名称的值被替换，字符串和反射字段按脱敏规则处理
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case f.Type == zapcore.SkipType:
+		case redact.IsSensitiveKey(f.Key):
+			f = zap.String(f.Key, redact.Mask)
+		case f.Type == zapcore.StringType:
+			f.String = redact.String(f.String)
+		case f.Type == zapcore.ReflectType:
+			f.Interface = redact.Value(f.Interface)
+		}
+		redacted[i] = f
+	}
+
+	return redacted
+}
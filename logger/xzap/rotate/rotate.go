@@ -0,0 +1,346 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	timeUtil "cxqi/common/kit/time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	compressSuffix = ".gz"
+	megabyte       = 1024 * 1024
+)
+
+// Config 日志文件分割配置
+type Config struct {
+	Filename string // 日志文件路径
+	MaxSize  int    // 单个日志文件的最大大小（MB），为0时不按大小分割
+	KeepDays int    // 分割后日志文件的保留天数，为0时永久保留
+	Compress bool   // 是否gzip压缩分割后的日志文件
+
+	// OnError 后台压缩和清理备份文件出错时的回调，如磁盘已满或权限不足，为空时输出到标准错误
+	OnError func(err error)
+}
+
+// Writer 按天和大小分割的日志文件写入器，
+// 分割后的日志文件命名为 文件名.日期[.序号][.gz]，如 access.log.2022-01-02.1.gz
+type Writer struct {
+	c    Config
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	day  string
+	now  func() time.Time
+
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// NewWriter 新建日志文件写入器
+func NewWriter(c Config) (*Writer, error) {
+	if c.Filename == "" || c.MaxSize < 0 || c.KeepDays < 0 {
+		return nil, errors.New("rotate: illegal rotate configure")
+	}
+
+	w := &Writer{c: c, now: func() time.Time { return timeUtil.Now() }}
+	if err := w.openExistingOrNew(); err != nil {
+		return nil, err
+	}
+	w.mill()
+
+	return w, nil
+}
+
+// Write 写入日志，日期变化或文件大小超出限制时先分割日志文件
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err = w.openExistingOrNew(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.day != w.today() {
+		err = w.rotate()
+	} else if max := int64(w.c.MaxSize) * megabyte; max > 0 && w.size > 0 && w.size+int64(len(p)) > max {
+		err = w.rotate()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Sync 将日志文件缓冲区数据刷新到磁盘
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	return w.file.Sync()
+}
+
+// Rotate 立即分割日志文件
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.openExistingOrNew(); err != nil {
+			return err
+		}
+	}
+
+	return w.rotate()
+}
+
+// Close 关闭日志文件并等待后台的压缩和清理任务完成，关闭后再次写入将重新打开日志文件
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	err := w.close()
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// close 关闭日志文件，调用方需持有锁
+func (w *Writer) close() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// openExistingOrNew 打开已存在的日志文件或新建日志文件，调用方需持有锁
+func (w *Writer) openExistingOrNew() error {
+	if err := os.MkdirAll(filepath.Dir(w.c.Filename), 0o755); err != nil {
+		return errors.WithMessage(err, "rotate: create log dir err")
+	}
+
+	f, err := os.OpenFile(w.c.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return errors.WithMessage(err, "rotate: open log file err")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.WithMessage(err, "rotate: stat log file err")
+	}
+
+	w.file = f
+	w.size = info.Size()
+	w.day = w.today()
+	if w.size > 0 {
+		// 已存在的日志文件归属于其最后修改的日期
+		w.day = info.ModTime().In(w.now().Location()).Format(dateLayout)
+	}
+
+	return nil
+}
+
+// rotate 将当前日志文件重命名为备份文件并新建日志文件，调用方需持有锁
+func (w *Writer) rotate() error {
+	if err := w.close(); err != nil {
+		return errors.WithMessage(err, "rotate: close log file err")
+	}
+
+	if err := os.Rename(w.c.Filename, w.backupName(w.day)); err != nil && !os.IsNotExist(err) {
+		return errors.WithMessage(err, "rotate: rename log file err")
+	}
+
+	if err := w.openExistingOrNew(); err != nil {
+		return err
+	}
+	w.mill()
+
+	return nil
+}
+
+// backupName 获取指定日期未被占用的备份文件名称
+func (w *Writer) backupName(day string) string {
+	base := w.c.Filename + "." + day
+	for i := 0; ; i++ {
+		name := base
+		if i > 0 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		if !exists(name) && !exists(name+compressSuffix) {
+			return name
+		}
+	}
+}
+
+// today 获取当前日期
+func (w *Writer) today() string {
+	return w.now().Format(dateLayout)
+}
+
+// mill 在后台压缩和清理备份文件
+func (w *Writer) mill() {
+	if !w.c.Compress && w.c.KeepDays == 0 {
+		return
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.millRun(); err != nil {
+			w.onError(err)
+		}
+	}()
+}
+
+// onError 报告后台压缩和清理备份文件的错误
+func (w *Writer) onError(err error) {
+	if w.c.OnError != nil {
+		w.c.OnError(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %v\n", w.now().Format(time.RFC3339), err)
+}
+
+// millRun 删除超出保留天数的备份文件，压缩未压缩的备份文件
+func (w *Writer) millRun() error {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	now := w.now()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day()-w.c.KeepDays, 0, 0, 0, 0, now.Location())
+
+	var errs []string
+	for _, b := range backups {
+		if w.c.KeepDays > 0 && b.date.Before(cutoff) {
+			if err := os.Remove(b.name); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+
+		if w.c.Compress && !strings.HasSuffix(b.name, compressSuffix) {
+			if err := compressFile(b.name); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("rotate: mill backups err: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// backup 备份文件
+type backup struct {
+	name string
+	date time.Time
+}
+
+// backups 获取全部备份文件，按日期升序排列
+func (w *Writer) backups() ([]backup, error) {
+	dir := filepath.Dir(w.c.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "rotate: read log dir err")
+	}
+
+	prefix := filepath.Base(w.c.Filename) + "."
+	loc := w.now().Location()
+
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+
+		suffix := strings.TrimPrefix(e.Name(), prefix)
+		if len(suffix) < len(dateLayout) {
+			continue
+		}
+		date, err := time.ParseInLocation(dateLayout, suffix[:len(dateLayout)], loc)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{name: filepath.Join(dir, e.Name()), date: date})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].date.Before(backups[j].date)
+	})
+
+	return backups, nil
+}
+
+// compressFile gzip压缩文件，压缩成功后删除原文件
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(name)
+}
+
+// exists 判断文件是否存在
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,159 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWriter(t *testing.T) {
+	_, err := NewWriter(Config{})
+	assert.EqualError(t, err, "rotate: illegal rotate configure")
+
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Filename: filepath.Join(dir, "sub", "access.log")})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Sync())
+	assert.Equal(t, "hello\n", readFile(t, filepath.Join(dir, "sub", "access.log")))
+
+	// 关闭后再次写入将重新打开日志文件
+	require.NoError(t, w.Close())
+	_, err = w.Write([]byte("world\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\nworld\n", readFile(t, filepath.Join(dir, "sub", "access.log")))
+}
+
+func TestWriter_RotateByDay(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 23, 59, 0, 0, time.Local)
+	w := newTestWriter(t, Config{Filename: filepath.Join(dir, "access.log")}, &now)
+
+	write(t, w, "day1\n")
+	now = now.Add(2 * time.Minute)
+	write(t, w, "day2\n")
+
+	assert.Equal(t, []string{"access.log", "access.log.2022-01-01"}, listDir(t, dir))
+	assert.Equal(t, "day1\n", readFile(t, filepath.Join(dir, "access.log.2022-01-01")))
+	assert.Equal(t, "day2\n", readFile(t, filepath.Join(dir, "access.log")))
+}
+
+func TestWriter_RotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)
+	w := newTestWriter(t, Config{Filename: filepath.Join(dir, "access.log"), MaxSize: 1}, &now)
+
+	line := strings.Repeat("a", megabyte/2-1) + "\n"
+	for i := 0; i < 5; i++ {
+		write(t, w, line)
+	}
+
+	assert.Equal(t, []string{"access.log", "access.log.2022-01-01", "access.log.2022-01-01.1"}, listDir(t, dir))
+	assert.Equal(t, line, readFile(t, filepath.Join(dir, "access.log")))
+}
+
+func TestWriter_CompressAndKeepDays(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.Local)
+	w := newTestWriter(t, Config{Filename: filepath.Join(dir, "error.log"), KeepDays: 3, Compress: true}, &now)
+	for _, name := range []string{"error.log.2021-12-01", "error.log.2021-12-30.gz", "other.log.2021-12-01"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644))
+	}
+
+	write(t, w, "day1\n")
+	now = now.AddDate(0, 0, 1)
+	write(t, w, "day2\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"error.log", "error.log.2021-12-30.gz", "error.log.2022-01-01.gz", "other.log.2021-12-01"}, listDir(t, dir))
+
+	f, err := os.Open(filepath.Join(dir, "error.log.2022-01-01.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "day1\n", string(b))
+
+	// 超出保留天数的备份文件被删除
+	now = now.AddDate(0, 0, 3)
+	require.NoError(t, w.millRun())
+	assert.Equal(t, []string{"error.log", "other.log.2021-12-01"}, listDir(t, dir))
+}
+
+func TestWriter_MillError(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.Local)
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	w := newTestWriter(t, Config{Filename: filepath.Join(dir, "error.log"), Compress: true, OnError: func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}}, &now)
+
+	// 压缩目标路径被目录占用，压缩失败
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "error.log.2021-12-31"), []byte("old\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "error.log.2021-12-31.gz"), 0o755))
+
+	write(t, w, "day1\n")
+	now = now.AddDate(0, 0, 1)
+	write(t, w, "day2\n")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "rotate: mill backups err")
+}
+
+func newTestWriter(t *testing.T, c Config, now *time.Time) *Writer {
+	w, err := NewWriter(c)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w.now = func() time.Time { return *now }
+	t.Cleanup(func() { _ = w.Close() })
+
+	return w
+}
+
+func write(t *testing.T, w *Writer, s string) {
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	w.wg.Wait()
+}
+
+func readFile(t *testing.T, name string) string {
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
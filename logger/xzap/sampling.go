@@ -0,0 +1,183 @@
+package xzap
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+)
+
+const (
+	// RepeatedKey 重复日志合并次数字段
+	RepeatedKey = "repeated"
+
+	// DefaultSamplingInterval 默认采样周期
+	DefaultSamplingInterval = time.Second
+
+	// maxDedupEntries 合并重复日志时最多跟踪的日志条数，超出后输出全部合并结果
+	maxDedupEntries = 4096
+)
+
+// newSamplingCore 根据采样配置包装zap core，先合并重复日志，再按级别和消息采样
+func newSamplingCore(c logging.SamplingConf, core zapcore.Core) zapcore.Core {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultSamplingInterval
+	}
+
+	if c.Dedup {
+		core = &dedupCore{Core: core, state: &dedupState{interval: interval, entries: map[dedupKey]*dedupEntry{}}}
+	}
+	if c.Initial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, interval, c.Initial, c.Thereafter)
+	}
+
+	return core
+}
+
+// dedupKey 重复日志的判断依据
+type dedupKey struct {
+	level   zapcore.Level
+	name    string
+	message string
+}
+
+// dedupEntry 周期内重复的日志
+type dedupEntry struct {
+	core  zapcore.Core
+	ent   zapcore.Entry
+	start time.Time
+	count int
+}
+
+// dedupState 重复日志的合并状态，同一个core通过With派生的core共享
+type dedupState struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	entries   map[dedupKey]*dedupEntry
+	lastSweep time.Time
+	timer     *time.Timer
+}
+
+// dedupCore 合并周期内重复日志的zap core，
+// 周期内相同级别和消息的日志只记录首条，周期结束时由定时器输出一条附带重复次数的日志
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+// With 添加字段
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+// Check 判断日志条目是否需要记录，周期内重复的日志只计数
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	key := dedupKey{level: ent.Level, name: ent.LoggerName, message: ent.Message}
+	s := c.state
+
+	s.mu.Lock()
+	expired := s.sweep(ent.Time, false)
+	e, ok := s.entries[key]
+	if ok && ent.Time.Sub(e.start) < s.interval {
+		e.core, e.ent = c.Core, ent
+		e.count++
+		if s.timer == nil {
+			// 定时器为空时不存在其他待输出的合并结果，按当前日志的周期结束时刻触发
+			s.timer = time.AfterFunc(time.Until(e.start.Add(s.interval)), s.flush)
+		}
+		s.mu.Unlock()
+		writeRepeated(expired)
+		return ce
+	}
+	if ok {
+		delete(s.entries, key)
+		if e.count > 0 {
+			expired = append(expired, e)
+		}
+	}
+	s.entries[key] = &dedupEntry{core: c.Core, ent: ent, start: ent.Time}
+	s.mu.Unlock()
+
+	writeRepeated(expired)
+	return c.Core.Check(ent, ce)
+}
+
+// Sync 输出全部合并结果并刷新缓冲区
+func (c *dedupCore) Sync() error {
+	c.state.mu.Lock()
+	expired := c.state.sweep(time.Now(), true)
+	if c.state.timer != nil {
+		c.state.timer.Stop()
+		c.state.timer = nil
+	}
+	c.state.mu.Unlock()
+
+	writeRepeated(expired)
+	return c.Core.Sync()
+}
+
+// flush 定时输出周期已结束的合并结果，使重复日志停止后无需等待后续日志或Sync即可输出重复次数
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	s.timer = nil
+	expired := s.expire(time.Now(), false)
+
+	// 仍有待输出的合并结果时，在其中最早的周期结束时刻再次触发
+	var next time.Time
+	for _, e := range s.entries {
+		if e.count > 0 && (next.IsZero() || e.start.Before(next)) {
+			next = e.start
+		}
+	}
+	if !next.IsZero() {
+		s.timer = time.AfterFunc(time.Until(next.Add(s.interval)), s.flush)
+	}
+	s.mu.Unlock()
+
+	writeRepeated(expired)
+}
+
+// sweep 按周期移除已过期的重复日志并返回需要输出合并结果的日志，调用方需持有锁
+func (s *dedupState) sweep(now time.Time, all bool) []*dedupEntry {
+	all = all || len(s.entries) >= maxDedupEntries
+	if !all && now.Sub(s.lastSweep) < s.interval {
+		return nil
+	}
+	s.lastSweep = now
+
+	return s.expire(now, all)
+}
+
+// expire 移除已过期的重复日志并返回需要输出合并结果的日志，调用方需持有锁
+func (s *dedupState) expire(now time.Time, all bool) []*dedupEntry {
+	var expired []*dedupEntry
+	for k, e := range s.entries {
+		if !all && now.Sub(e.start) < s.interval {
+			continue
+		}
+
+		delete(s.entries, k)
+		if e.count > 0 {
+			expired = append(expired, e)
+		}
+	}
+
+	return expired
+}
+
+// writeRepeated 输出附带重复次数的合并结果
+func writeRepeated(entries []*dedupEntry) {
+	for _, e := range entries {
+		if ce := e.core.Check(e.ent, nil); ce != nil {
+			ce.Write(zap.Int(RepeatedKey, e.count))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package xzap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	logging "cxqi/common/logger"
+)
+
+func TestSampling(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(newSamplingCore(logging.SamplingConf{Interval: time.Minute, Initial: 2, Thereafter: 3}, obs))
+
+	for i := 0; i < 10; i++ {
+		l.Error("request handle err")
+		l.Warn("slow sql")
+	}
+
+	assert.Equal(t, 4, logs.FilterMessage("request handle err").Len())
+	assert.Equal(t, 4, logs.FilterMessage("slow sql").Len())
+}
+
+func TestDedup(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newSamplingCore(logging.SamplingConf{Interval: time.Second, Dedup: true}, obs)
+
+	start := time.Now()
+	write := func(msg string, offset time.Duration) {
+		ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: msg, Time: start.Add(offset)}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		write("request handle err", time.Duration(i)*time.Millisecond)
+	}
+	write("other err", 0)
+	require.Equal(t, 2, logs.Len())
+
+	// 周期结束后输出重复次数
+	write("request handle err", 2*time.Second)
+	require.Equal(t, 4, logs.Len())
+	assert.Equal(t, map[string]interface{}{RepeatedKey: int64(99)}, logs.All()[2].ContextMap())
+	assert.Equal(t, "request handle err", logs.All()[3].Message)
+	assert.Empty(t, logs.All()[3].Context)
+
+	write("request handle err", 2*time.Second+time.Millisecond)
+	require.NoError(t, core.Sync())
+	require.Equal(t, 5, logs.Len())
+	assert.Equal(t, map[string]interface{}{RepeatedKey: int64(1)}, logs.All()[4].ContextMap())
+}
+
+func TestDedup_Timer(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(newSamplingCore(logging.SamplingConf{Interval: 50 * time.Millisecond, Dedup: true}, obs))
+
+	for i := 0; i < 10; i++ {
+		l.Error("request handle err")
+	}
+	l.Warn("slow sql")
+	l.Warn("slow sql")
+	require.Equal(t, 2, logs.Len())
+
+	// 重复日志停止后，周期结束时无需后续日志或Sync即输出重复次数
+	require.Eventually(t, func() bool { return logs.Len() == 4 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(9), logs.FilterMessage("request handle err").FilterFieldKey(RepeatedKey).All()[0].ContextMap()[RepeatedKey])
+	assert.Equal(t, int64(1), logs.FilterMessage("slow sql").FilterFieldKey(RepeatedKey).All()[0].ContextMap()[RepeatedKey])
+}
@@ -0,0 +1,161 @@
+package xzap
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler 基于xzap的slog.Handler，日志附带上下文中的链路追踪id和请求tags
+type SlogHandler struct {
+	logger *zap.Logger
+	fields []zap.Field
+}
+
+// NewSlogHandler 新建基于xzap的slog.Handler，未指定zap日志记录器时使用全局日志记录器
+func NewSlogHandler(l ...*zap.Logger) *SlogHandler {
+	h := &SlogHandler{}
+	if len(l) > 0 {
+		h.logger = l[0]
+	}
+
+	return h
+}
+
+// Slog 获取基于全局日志记录器的slog日志记录器
+func Slog() *slog.Logger {
+	return slog.New(NewSlogHandler())
+}
+
+// Enabled 判断日志级别是否启用
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.zapLogger().Core().Enabled(slogLevel(level))
+}
+
+// Handle 记录日志，调用位置和时间以slog记录为准
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.zapLogger().Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	fields := ContextFields(ctx)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+
+	return nil
+}
+
+// WithAttrs 返回附带字段的slog.Handler
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]zap.Field(nil), h.fields...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+
+	return &SlogHandler{logger: h.logger, fields: fields}
+}
+
+// WithGroup 返回之后的字段嵌套在指定分组中的slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogHandler{logger: h.logger, fields: append(append([]zap.Field(nil), h.fields...), zap.Namespace(name))}
+}
+
+// zapLogger 获取zap日志记录器
+func (h *SlogHandler) zapLogger() *zap.Logger {
+	if h.logger != nil {
+		return h.logger
+	}
+
+	return L()
+}
+
+// slogLevel 将slog日志级别转换为zap日志级别
+func slogLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case l < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case l < slog.LevelError:
+		return zapcore.WarnLevel
+	case l < slog.LevelError+4:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.DPanicLevel
+	}
+}
+
+// appendAttr 将slog字段转换为zap字段，空字段被忽略，空名称的分组字段展开
+func appendAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, groupMarshaler(attrs)))
+	default:
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+}
+
+// groupMarshaler slog分组字段的zap对象编码器
+type groupMarshaler []slog.Attr
+
+// MarshalLogObject 编码分组字段
+func (g groupMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return nil
+}
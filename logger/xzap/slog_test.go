@@ -0,0 +1,63 @@
+package xzap
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	logging "cxqi/common/logger"
+)
+
+func TestSlogHandler(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer ReplaceLogger(zap.New(core))()
+
+	ctx := logging.SetInContext(context.Background(), logging.NewTags().Set(logging.TagUserId, int64(1000)))
+	l := Slog().With("service", "test").WithGroup("req")
+	l.DebugContext(ctx, "debug")
+	l.InfoContext(ctx, "info", "id", 1, slog.Group("page", "size", 10), "err", errors.New("boom"))
+	l.WarnContext(ctx, "warn", "cost", time.Second, slog.Group("", "inline", true))
+
+	require.Equal(t, 2, logs.Len())
+	info := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, info.Level)
+	assert.Equal(t, "info", info.Message)
+	assert.True(t, info.Caller.Defined)
+	assert.Contains(t, info.Caller.File, "slog_test.go")
+	assert.Equal(t, map[string]interface{}{
+		logging.TagUserId: int64(1000),
+		"service":         "test",
+		"req": map[string]interface{}{
+			"id":   int64(1),
+			"page": map[string]interface{}{"size": int64(10)},
+			"err":  "boom",
+		},
+	}, info.ContextMap())
+
+	warn := logs.All()[1]
+	assert.Equal(t, zapcore.WarnLevel, warn.Level)
+	assert.Equal(t, map[string]interface{}{"cost": time.Second, "inline": true}, warn.ContextMap()["req"])
+}
+
+func TestFromSlog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := logging.FromSlog(slog.New(NewSlogHandler(zap.New(core))))
+
+	l.With("k", "v").Infow("info", "a", 1)
+	l.Debugf("debug %d", 1)
+	assert.Panics(t, func() { l.Panicf("panic %d", 1) })
+
+	require.Equal(t, 3, logs.Len())
+	assert.Equal(t, map[string]interface{}{"k": "v", "a": int64(1)}, logs.All()[0].ContextMap())
+	assert.Contains(t, logs.All()[0].Caller.File, "slog_test.go")
+	assert.Equal(t, "debug 1", logs.All()[1].Message)
+	assert.Equal(t, zapcore.DPanicLevel, logs.All()[2].Level)
+}
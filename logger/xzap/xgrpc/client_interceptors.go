@@ -0,0 +1,189 @@
+package xgrpc
+
+import (
+	"context"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+// UnaryClientInterceptor 客户端一元日志拦截器，记录目标方法、耗时和状态码
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := evaluateClientOptions(opts)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		logFinalClientLine(ctx, o, "finished client unary call", method, cc, start, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor 客户端流日志拦截器，记录目标方法、耗时和状态码，
+// 日志在流建立失败、发送消息失败或接收消息结束时记录，非服务端流调用在接收到唯一的响应后即结束
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := evaluateClientOptions(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			logFinalClientLine(ctx, o, "finished client streaming call", method, cc, start, err)
+			return nil, err
+		}
+
+		return &finishingClientStream{ClientStream: cs, serverStreams: desc.ServerStreams, finish: func(err error) {
+			logFinalClientLine(ctx, o, "finished client streaming call", method, cc, start, err)
+		}}, nil
+	}
+}
+
+// PayloadUnaryClientInterceptor 客户端一元载荷日志拦截器，记录经决策器允许的请求和响应载荷
+func PayloadUnaryClientInterceptor(decider logging.ClientLoggingDecider, opts ...Option) grpc.UnaryClientInterceptor {
+	o := evaluateClientOptions(opts)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if !decider(ctx, method) {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		l := xzap.Ctx(ctx).With(clientCallFields(method, cc, time.Now())...)
+		logProtoMessage(l, o, req, RequestContentKey, "client request payload logged as grpc.request.content field")
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if err == nil {
+			logProtoMessage(l, o, reply, ResponseContentKey, "client response payload logged as grpc.response.content field")
+		}
+
+		return err
+	}
+}
+
+// PayloadStreamClientInterceptor 客户端流载荷日志拦截器，记录经决策器允许的流消息载荷
+func PayloadStreamClientInterceptor(decider logging.ClientLoggingDecider, opts ...Option) grpc.StreamClientInterceptor {
+	o := evaluateClientOptions(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !decider(ctx, method) {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		l := xzap.Ctx(ctx).With(clientCallFields(method, cc, time.Now())...)
+		return &loggingClientStream{ClientStream: cs, logger: l, opts: o}, nil
+	}
+}
+
+// finishingClientStream 在流结束时执行回调的客户端流对象
+type finishingClientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	finish        func(err error)
+}
+
+// SendMsg 发送消息，发送失败时执行回调，
+// io.EOF表示流已被服务端终止，真实状态需由RecvMsg获取，此时不执行回调
+func (f *finishingClientStream) SendMsg(m interface{}) error {
+	err := f.ClientStream.SendMsg(m)
+	if err != nil && err != io.EOF {
+		f.once.Do(func() { f.finish(err) })
+	}
+
+	return err
+}
+
+// CloseSend 关闭发送方向，关闭失败时执行回调
+func (f *finishingClientStream) CloseSend() error {
+	err := f.ClientStream.CloseSend()
+	if err != nil {
+		f.once.Do(func() { f.finish(err) })
+	}
+
+	return err
+}
+
+// RecvMsg 接收消息，在流结束或非服务端流调用接收到响应时执行回调
+func (f *finishingClientStream) RecvMsg(m interface{}) error {
+	err := f.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		f.once.Do(func() { f.finish(nil) })
+	case err != nil:
+		f.once.Do(func() { f.finish(err) })
+	case !f.serverStreams:
+		f.once.Do(func() { f.finish(nil) })
+	}
+
+	return err
+}
+
+// loggingClientStream 记录载荷日志的客户端流对象
+type loggingClientStream struct {
+	grpc.ClientStream
+	logger *zap.Logger
+	opts   *options
+}
+
+// SendMsg 发送消息并记录载荷日志
+func (l *loggingClientStream) SendMsg(m interface{}) error {
+	err := l.ClientStream.SendMsg(m)
+	if err == nil {
+		logProtoMessage(l.logger, l.opts, m, RequestContentKey, "client request payload logged as grpc.request.content field")
+	}
+
+	return err
+}
+
+// RecvMsg 接收消息并记录载荷日志
+func (l *loggingClientStream) RecvMsg(m interface{}) error {
+	err := l.ClientStream.RecvMsg(m)
+	if err == nil {
+		logProtoMessage(l.logger, l.opts, m, ResponseContentKey, "client response payload logged as grpc.response.content field")
+	}
+
+	return err
+}
+
+// logFinalClientLine 记录客户端调用结束日志
+func logFinalClientLine(ctx context.Context, o *options, msg, method string, cc *grpc.ClientConn, start time.Time, err error) {
+	if !o.shouldLog(method, err) {
+		return
+	}
+
+	code := o.codeFunc(err)
+	if ce := xzap.Ctx(ctx).Check(o.levelFunc(code), msg); ce != nil {
+		fields := clientCallFields(method, cc, start)
+		fields = append(fields, codeFields(err, code)...)
+		fields = append(fields, o.durationFunc(time.Since(start)))
+		ce.Write(fields...)
+	}
+}
+
+// clientCallFields 客户端调用日志字段
+func clientCallFields(method string, cc *grpc.ClientConn, start time.Time) []zap.Field {
+	fields := []zap.Field{
+		zap.String(SystemKey, SystemValue),
+		zap.String(KindKey, KindClient),
+		zap.String(ServiceKey, path.Dir(method)[1:]),
+		zap.String(MethodKey, path.Base(method)),
+		zap.String(StartTimeKey, start.Format(time.RFC3339Nano)),
+	}
+
+	if cc != nil {
+		fields = append(fields, zap.String(TargetKey, cc.Target()))
+	}
+
+	return fields
+}
@@ -0,0 +1,170 @@
+package xgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	logging "cxqi/common/logger"
+)
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	ctx := logging.SetInContext(context.Background(), logging.NewTags().Set("request_id", "abc"))
+	interceptor := UnaryClientInterceptor()
+	err := interceptor(ctx, "/test.Service/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	require.Error(t, err)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "finished client unary call", entries[0].Message)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, KindClient, fields[KindKey])
+	assert.Equal(t, "test.Service", fields[ServiceKey])
+	assert.Equal(t, "Method", fields[MethodKey])
+	assert.Equal(t, "Unavailable", fields[CodeKey])
+	assert.Equal(t, "abc", fields["request_id"])
+}
+
+func TestDefaultClientCodeToLevel(t *testing.T) {
+	assert.Equal(t, zapcore.DebugLevel, DefaultClientCodeToLevel(codes.OK))
+	assert.Equal(t, zapcore.DebugLevel, DefaultClientCodeToLevel(codes.InvalidArgument))
+	assert.Equal(t, zapcore.InfoLevel, DefaultClientCodeToLevel(codes.Unavailable))
+	assert.Equal(t, zapcore.InfoLevel, DefaultClientCodeToLevel(codes.Internal))
+	assert.Equal(t, zapcore.InfoLevel, DefaultClientCodeToLevel(codes.Code(10001)))
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	interceptor := StreamClientInterceptor()
+	cs, err := interceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Service/Stream",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{msgs: []string{"a", "b"}}, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.Len())
+
+	for {
+		if err := cs.RecvMsg(&wrapperspb.StringValue{}); err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+	}
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "finished client streaming call", entries[0].Message)
+	assert.Equal(t, "OK", entries[0].ContextMap()[CodeKey])
+}
+
+func TestStreamClientInterceptor_ClientStreaming(t *testing.T) {
+	logs := observe(t)
+
+	interceptor := StreamClientInterceptor()
+	desc := &grpc.StreamDesc{ClientStreams: true}
+	cs, err := interceptor(context.Background(), desc, nil, "/test.Service/Upload",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{msgs: []string{"done"}}, nil
+		})
+	require.NoError(t, err)
+
+	// CloseAndRecv
+	require.NoError(t, cs.SendMsg(wrapperspb.String("a")))
+	require.NoError(t, cs.CloseSend())
+	assert.Equal(t, 0, logs.Len())
+	require.NoError(t, cs.RecvMsg(&wrapperspb.StringValue{}))
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "finished client streaming call", entries[0].Message)
+	assert.Equal(t, "OK", entries[0].ContextMap()[CodeKey])
+
+	// 发送失败
+	cs, err = interceptor(context.Background(), desc, nil, "/test.Service/Upload",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{sendErr: status.Error(codes.Unavailable, "unavailable")}, nil
+		})
+	require.NoError(t, err)
+	assert.Error(t, cs.SendMsg(wrapperspb.String("a")))
+	assert.Error(t, cs.SendMsg(wrapperspb.String("b")))
+
+	entries = logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Unavailable", entries[0].ContextMap()[CodeKey])
+
+	// 关闭发送方向失败
+	cs, err = interceptor(context.Background(), desc, nil, "/test.Service/Upload",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{closeErr: status.Error(codes.Internal, "internal")}, nil
+		})
+	require.NoError(t, err)
+	assert.Error(t, cs.CloseSend())
+
+	entries = logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Internal", entries[0].ContextMap()[CodeKey])
+}
+
+func TestPayloadUnaryClientInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	interceptor := PayloadUnaryClientInterceptor(func(ctx context.Context, methodName string) bool {
+		return methodName == "/test.Service/Logged"
+	})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		proto.Merge(reply.(proto.Message), wrapperspb.Bool(true))
+		return nil
+	}
+
+	require.NoError(t, interceptor(context.Background(), "/test.Service/Logged", wrapperspb.String("hello"), &wrapperspb.BoolValue{}, nil, invoker))
+	require.NoError(t, interceptor(context.Background(), "/test.Service/Ignored", wrapperspb.String("hello"), &wrapperspb.BoolValue{}, nil, invoker))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "client request payload logged as grpc.request.content field", entries[0].Message)
+	assert.JSONEq(t, `"hello"`, string(entries[0].ContextMap()[RequestContentKey].(json.RawMessage)))
+	assert.Equal(t, "client response payload logged as grpc.response.content field", entries[1].Message)
+	assert.JSONEq(t, `true`, string(entries[1].ContextMap()[ResponseContentKey].(json.RawMessage)))
+}
+
+// fakeClientStream 测试用客户端流对象
+type fakeClientStream struct {
+	grpc.ClientStream
+	msgs     []string
+	sendErr  error
+	closeErr error
+}
+
+func (f *fakeClientStream) SendMsg(interface{}) error {
+	return f.sendErr
+}
+
+func (f *fakeClientStream) CloseSend() error {
+	return f.closeErr
+}
+
+func (f *fakeClientStream) RecvMsg(m interface{}) error {
+	if len(f.msgs) == 0 {
+		return io.EOF
+	}
+
+	m.(*wrapperspb.StringValue).Value = f.msgs[0]
+	f.msgs = f.msgs[1:]
+	return nil
+}
@@ -1 +1,38 @@
 package xgrpc
+
+const (
+	// SystemKey 系统类型字段
+	SystemKey = "system"
+	// SystemValue 系统类型字段值
+	SystemValue = "grpc"
+	// KindKey 调用类型字段
+	KindKey = "span.kind"
+	// KindServer 调用类型：服务端
+	KindServer = "server"
+	// KindClient 调用类型：客户端
+	KindClient = "client"
+
+	// ServiceKey grpc服务名称字段
+	ServiceKey = "grpc.service"
+	// MethodKey grpc方法名称字段
+	MethodKey = "grpc.method"
+	// StartTimeKey grpc调用开始时间字段
+	StartTimeKey = "grpc.start_time"
+	// DeadlineKey grpc调用截止时间字段
+	DeadlineKey = "grpc.request.deadline"
+	// CodeKey grpc状态码字段
+	CodeKey = "grpc.code"
+	// BizCodeKey 业务状态码字段，仅错误链中含有业务错误时记录
+	BizCodeKey = "grpc.biz_code"
+	// DurationKey grpc调用耗时字段（毫秒）
+	DurationKey = "grpc.time_ms"
+	// TargetKey grpc客户端连接目标字段
+	TargetKey = "grpc.target"
+	// PeerAddressKey 对端地址字段
+	PeerAddressKey = "peer.address"
+
+	// RequestContentKey grpc请求载荷字段
+	RequestContentKey = "grpc.request.content"
+	// ResponseContentKey grpc响应载荷字段
+	ResponseContentKey = "grpc.response.content"
+)
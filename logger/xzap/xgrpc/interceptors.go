@@ -1 +1,188 @@
 package xgrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/redact"
+	"cxqi/common/logger/xzap"
+)
+
+// UnaryServerInterceptor 服务端一元日志拦截器，记录方法、耗时、对端地址和状态码
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if !o.shouldLog(info.FullMethod, err) {
+			return resp, err
+		}
+
+		code := o.codeFunc(err)
+		if ce := xzap.Ctx(ctx).Check(o.levelFunc(code), "finished unary call with code "+code.String()); ce != nil {
+			fields := serverCallFields(ctx, info.FullMethod, start)
+			fields = append(fields, codeFields(err, code)...)
+			fields = append(fields, o.durationFunc(time.Since(start)))
+			ce.Write(fields...)
+		}
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 服务端流日志拦截器，记录方法、耗时、对端地址和状态码
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		if !o.shouldLog(info.FullMethod, err) {
+			return err
+		}
+
+		ctx := ss.Context()
+		code := o.codeFunc(err)
+		if ce := xzap.Ctx(ctx).Check(o.levelFunc(code), "finished streaming call with code "+code.String()); ce != nil {
+			fields := serverCallFields(ctx, info.FullMethod, start)
+			fields = append(fields, codeFields(err, code)...)
+			fields = append(fields, o.durationFunc(time.Since(start)))
+			ce.Write(fields...)
+		}
+
+		return err
+	}
+}
+
+// PayloadUnaryServerInterceptor 服务端一元载荷日志拦截器，记录经决策器允许的请求和响应载荷
+func PayloadUnaryServerInterceptor(decider logging.ServerLoggingDecider, opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !decider(ctx, info.FullMethod, info.Server) {
+			return handler(ctx, req)
+		}
+
+		l := xzap.Ctx(ctx).With(serverCallFields(ctx, info.FullMethod, time.Now())...)
+		logProtoMessage(l, o, req, RequestContentKey, "server request payload logged as grpc.request.content field")
+		resp, err := handler(ctx, req)
+		if err == nil {
+			logProtoMessage(l, o, resp, ResponseContentKey, "server response payload logged as grpc.response.content field")
+		}
+
+		return resp, err
+	}
+}
+
+// PayloadStreamServerInterceptor 服务端流载荷日志拦截器，记录经决策器允许的流消息载荷
+func PayloadStreamServerInterceptor(decider logging.ServerLoggingDecider, opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if !decider(ctx, info.FullMethod, srv) {
+			return handler(srv, ss)
+		}
+
+		l := xzap.Ctx(ctx).With(serverCallFields(ctx, info.FullMethod, time.Now())...)
+		return handler(srv, &loggingServerStream{ServerStream: ss, logger: l, opts: o})
+	}
+}
+
+// loggingServerStream 记录载荷日志的服务端流对象
+type loggingServerStream struct {
+	grpc.ServerStream
+	logger *zap.Logger
+	opts   *options
+}
+
+// SendMsg 发送消息并记录载荷日志
+func (l *loggingServerStream) SendMsg(m interface{}) error {
+	err := l.ServerStream.SendMsg(m)
+	if err == nil {
+		logProtoMessage(l.logger, l.opts, m, ResponseContentKey, "server response payload logged as grpc.response.content field")
+	}
+
+	return err
+}
+
+// RecvMsg 接收消息并记录载荷日志
+func (l *loggingServerStream) RecvMsg(m interface{}) error {
+	err := l.ServerStream.RecvMsg(m)
+	if err == nil {
+		logProtoMessage(l.logger, l.opts, m, RequestContentKey, "server request payload logged as grpc.request.content field")
+	}
+
+	return err
+}
+
+// codeFields 错误、grpc状态码和业务状态码日志字段
+func codeFields(err error, code codes.Code) []zap.Field {
+	fields := []zap.Field{zap.Error(err), zap.String(CodeKey, code.String())}
+	if bizCode, ok := logging.ErrorBizCode(err); ok {
+		fields = append(fields, zap.Uint32(BizCodeKey, bizCode))
+	}
+
+	return fields
+}
+
+// serverCallFields 服务端调用日志字段
+func serverCallFields(ctx context.Context, fullMethod string, start time.Time) []zap.Field {
+	fields := []zap.Field{
+		zap.String(SystemKey, SystemValue),
+		zap.String(KindKey, KindServer),
+		zap.String(ServiceKey, path.Dir(fullMethod)[1:]),
+		zap.String(MethodKey, path.Base(fullMethod)),
+		zap.String(StartTimeKey, start.Format(time.RFC3339Nano)),
+	}
+
+	if d, ok := ctx.Deadline(); ok {
+		fields = append(fields, zap.String(DeadlineKey, d.Format(time.RFC3339Nano)))
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, zap.String(PeerAddressKey, p.Addr.String()))
+	}
+
+	return fields
+}
+
+// logProtoMessage 记录载荷日志，protobuf消息通过序列化器转换为json，开启脱敏时按规则脱敏
+func logProtoMessage(l *zap.Logger, o *options, m interface{}, key, msg string) {
+	ce := l.Check(zapcore.InfoLevel, msg)
+	if ce == nil {
+		return
+	}
+
+	pb, ok := m.(proto.Message)
+	if !ok {
+		if o.redact {
+			m = redact.Value(m)
+		}
+		ce.Write(zap.Any(key, m))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := o.marshaler.Marshal(&buf, pb); err != nil {
+		ce.Write(zap.String(key, "unable to marshal payload: "+err.Error()))
+		return
+	}
+
+	b := buf.Bytes()
+	if o.redact {
+		b = redact.JSON(b)
+	}
+	ce.Write(zap.Reflect(key, json.RawMessage(b)))
+}
@@ -0,0 +1,118 @@
+package xgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+)
+
+func observe(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.Cleanup(xzap.ReplaceLogger(zap.New(core)))
+	return logs
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}})
+
+	interceptor := UnaryServerInterceptor()
+	_, err := interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	_, err = interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errcode.ErrUnexpected
+	})
+	assert.Equal(t, errcode.ErrUnexpected, err)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "finished unary call with code OK", entries[0].Message)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "test.Service", fields[ServiceKey])
+	assert.Equal(t, "Method", fields[MethodKey])
+	assert.Equal(t, "127.0.0.1:8080", fields[PeerAddressKey])
+	assert.Equal(t, "OK", fields[CodeKey])
+	assert.Contains(t, fields, DurationKey)
+	assert.NotContains(t, fields, BizCodeKey)
+
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "Internal", entries[1].ContextMap()[CodeKey])
+	assert.Equal(t, uint32(errcode.ErrUnexpected.Code()), entries[1].ContextMap()[BizCodeKey])
+	assert.Equal(t, errcode.ErrUnexpected.Error(), entries[1].ContextMap()["error"])
+}
+
+func TestUnaryServerInterceptor_Decider(t *testing.T) {
+	logs := observe(t)
+
+	interceptor := UnaryServerInterceptor(WithDecider(func(methodName string, err error) bool {
+		return methodName != "/grpc.health.v1.Health/Check"
+	}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Method", logs.All()[0].ContextMap()[MethodKey])
+}
+
+func TestPayloadUnaryServerInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	interceptor := PayloadUnaryServerInterceptor(func(ctx context.Context, methodName string, servingObject interface{}) bool {
+		return methodName == "/test.Service/Logged"
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.Int64(1000), nil
+	}
+
+	_, err := interceptor(context.Background(), wrapperspb.String("hello"), &grpc.UnaryServerInfo{FullMethod: "/test.Service/Logged"}, handler)
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), wrapperspb.String("hello"), &grpc.UnaryServerInfo{FullMethod: "/test.Service/Ignored"}, handler)
+	require.NoError(t, err)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "server request payload logged as grpc.request.content field", entries[0].Message)
+	assert.JSONEq(t, `"hello"`, string(entries[0].ContextMap()[RequestContentKey].(json.RawMessage)))
+	assert.Equal(t, "server response payload logged as grpc.response.content field", entries[1].Message)
+	assert.JSONEq(t, `"1000"`, string(entries[1].ContextMap()[ResponseContentKey].(json.RawMessage)))
+}
+
+func TestPayloadUnaryServerInterceptor_Redact(t *testing.T) {
+	logs := observe(t)
+
+	req, err := structpb.NewStruct(map[string]interface{}{"password": "secret"})
+	require.NoError(t, err)
+	always := func(ctx context.Context, methodName string, servingObject interface{}) bool { return true }
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, errcode.ErrUnexpected }
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	_, _ = PayloadUnaryServerInterceptor(always)(context.Background(), req, info, handler)
+	_, _ = PayloadUnaryServerInterceptor(always, WithRedact(true))(context.Background(), req, info, handler)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.JSONEq(t, `{"password":"secret"}`, string(entries[0].ContextMap()[RequestContentKey].(json.RawMessage)))
+	assert.JSONEq(t, `{"password":"******"}`, string(entries[1].ContextMap()[RequestContentKey].(json.RawMessage)))
+}
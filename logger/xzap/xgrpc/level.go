@@ -0,0 +1,129 @@
+package xgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+)
+
+// LevelServiceName 日志级别管理服务名称
+const LevelServiceName = "xzap.LevelService"
+
+// LevelServer 日志级别管理服务，
+// GetLevels获取全部模块的日志级别，
+// SetLevel通过module、level和ttl字段设置模块的日志级别，返回设置后全部模块的日志级别。
+// 服务没有独立的proto定义，请求和响应均为标准的protobuf类型，客户端可直接调用：
+//
+//	in, _ := structpb.NewStruct(map[string]interface{}{"module": "gorm", "level": "debug", "ttl": "10m"})
+//	out := &structpb.Struct{}
+//	err := cc.Invoke(ctx, "/xzap.LevelService/SetLevel", in, out)
+type LevelServer interface {
+	GetLevels(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+	SetLevel(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error)
+}
+
+// LevelServiceDesc 日志级别管理服务描述
+var LevelServiceDesc = grpc.ServiceDesc{
+	ServiceName: LevelServiceName,
+	HandlerType: (*LevelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLevels",
+			Handler:    getLevelsHandler,
+		},
+		{
+			MethodName: "SetLevel",
+			Handler:    setLevelHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterLevelServer 注册日志级别管理服务
+func RegisterLevelServer(s grpc.ServiceRegistrar) {
+	s.RegisterService(&LevelServiceDesc, NewLevelServer())
+}
+
+// NewLevelServer 新建日志级别管理服务
+func NewLevelServer() LevelServer {
+	return &levelServer{}
+}
+
+// levelServer 日志级别管理服务的默认实现
+type levelServer struct{}
+
+// GetLevels 获取全部模块的日志级别
+func (s *levelServer) GetLevels(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	return levelsStruct()
+}
+
+// SetLevel 设置模块的日志级别
+func (s *levelServer) SetLevel(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	fields := in.GetFields()
+	err := xzap.ChangeLevel(
+		fields["module"].GetStringValue(),
+		fields["level"].GetStringValue(),
+		fields["ttl"].GetStringValue(),
+	)
+	if err != nil {
+		return nil, errcode.NewCustomErr(err.Error())
+	}
+
+	return levelsStruct()
+}
+
+// levelsStruct 将全部模块的日志级别转换为protobuf结构体
+func levelsStruct() (*structpb.Struct, error) {
+	levels := xzap.Levels()
+	m := make(map[string]interface{}, len(levels))
+	for k, v := range levels {
+		m[k] = v
+	}
+
+	return structpb.NewStruct(m)
+}
+
+func getLevelsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LevelServer).GetLevels(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + LevelServiceName + "/GetLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LevelServer).GetLevels(ctx, req.(*emptypb.Empty))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+func setLevelHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LevelServer).SetLevel(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + LevelServiceName + "/SetLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LevelServer).SetLevel(ctx, req.(*structpb.Struct))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
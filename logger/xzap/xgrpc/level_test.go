@@ -0,0 +1,35 @@
+package xgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+)
+
+func TestLevelServer(t *testing.T) {
+	defer xzap.ResetLevel("grpc_module")
+
+	s := NewLevelServer()
+	in, err := structpb.NewStruct(map[string]interface{}{"module": "grpc_module", "level": "error"})
+	require.NoError(t, err)
+
+	out, err := s.SetLevel(context.Background(), in)
+	require.NoError(t, err)
+	assert.Equal(t, "error", out.GetFields()["grpc_module"].GetStringValue())
+
+	out, err = s.GetLevels(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "error", out.GetFields()["grpc_module"].GetStringValue())
+
+	in, err = structpb.NewStruct(map[string]interface{}{"module": "grpc_module", "level": "unknown"})
+	require.NoError(t, err)
+	_, err = s.SetLevel(context.Background(), in)
+	assert.Equal(t, errcode.CodeCustom, int(errcode.ParseErr(err).Code()))
+}
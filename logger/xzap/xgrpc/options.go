@@ -0,0 +1,172 @@
+package xgrpc
+
+import (
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	logging "cxqi/common/logger"
+)
+
+// DefaultJsonPbMarshaler 默认的protobuf消息序列化器
+var DefaultJsonPbMarshaler logging.JsonPbMarshaler = &jsonpbMarshaler{}
+
+// CodeToLevel 定义grpc状态码映射日志级别
+type CodeToLevel func(code codes.Code) zapcore.Level
+
+// DurationToField 定义调用耗时映射日志字段
+type DurationToField func(duration time.Duration) zap.Field
+
+// RequestFieldExtractorFunc 定义从请求中提取tags字段
+type RequestFieldExtractorFunc func(fullMethod string, req interface{}) map[string]interface{}
+
+// Option 日志拦截器可选配置
+type Option func(*options)
+
+// options 日志拦截器配置
+type options struct {
+	shouldLog    logging.Decider
+	codeFunc     logging.ErrorToCode
+	levelFunc    CodeToLevel
+	durationFunc DurationToField
+	marshaler    logging.JsonPbMarshaler
+	extractor    RequestFieldExtractorFunc
+	redact       bool
+}
+
+// evaluateServerOptions 计算服务端日志拦截器配置
+func evaluateServerOptions(opts []Option) *options {
+	return evaluateOptions(DefaultCodeToLevel, opts)
+}
+
+// evaluateClientOptions 计算客户端日志拦截器配置
+func evaluateClientOptions(opts []Option) *options {
+	return evaluateOptions(DefaultClientCodeToLevel, opts)
+}
+
+// evaluateOptions 以指定的默认级别映射函数计算日志拦截器配置
+func evaluateOptions(levelFunc CodeToLevel, opts []Option) *options {
+	o := &options{
+		shouldLog:    logging.DefaultDeciderMethod,
+		codeFunc:     logging.DefaultErrorToCode,
+		levelFunc:    levelFunc,
+		durationFunc: DefaultDurationToField,
+		marshaler:    DefaultJsonPbMarshaler,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithDecider 自定义是否记录调用日志的决策器
+func WithDecider(f logging.Decider) Option {
+	return func(o *options) {
+		o.shouldLog = f
+	}
+}
+
+// WithCodes 自定义error映射grpc状态码的函数
+func WithCodes(f logging.ErrorToCode) Option {
+	return func(o *options) {
+		o.codeFunc = f
+	}
+}
+
+// WithLevels 自定义grpc状态码映射日志级别的函数
+func WithLevels(f CodeToLevel) Option {
+	return func(o *options) {
+		o.levelFunc = f
+	}
+}
+
+// WithDurationField 自定义调用耗时映射日志字段的函数
+func WithDurationField(f DurationToField) Option {
+	return func(o *options) {
+		o.durationFunc = f
+	}
+}
+
+// WithMarshaler 自定义载荷日志的protobuf消息序列化器
+func WithMarshaler(m logging.JsonPbMarshaler) Option {
+	return func(o *options) {
+		o.marshaler = m
+	}
+}
+
+// WithRedact 按脱敏规则记录载荷日志，与日志配置的redact保持一致
+func WithRedact(enabled bool) Option {
+	return func(o *options) {
+		o.redact = enabled
+	}
+}
+
+// WithFieldExtractor 自定义从请求中提取tags字段的函数
+func WithFieldExtractor(f RequestFieldExtractorFunc) Option {
+	return func(o *options) {
+		o.extractor = f
+	}
+}
+
+// WithRequestFields 指定从protobuf请求消息中提取为tags的顶层字段名称
+func WithRequestFields(names ...string) Option {
+	return func(o *options) {
+		o.extractor = ProtoFieldExtractor(names...)
+	}
+}
+
+// DefaultCodeToLevel grpc状态码映射日志级别的默认实现，
+// 非标准的业务状态码记录为Warn级别
+func DefaultCodeToLevel(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return zapcore.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return zapcore.WarnLevel
+	case codes.Unknown, codes.Unimplemented, codes.Internal, codes.DataLoss:
+		return zapcore.ErrorLevel
+	}
+
+	return zapcore.WarnLevel
+}
+
+// DefaultClientCodeToLevel 客户端grpc状态码映射日志级别的默认实现，
+// 请求类错误记录为Debug级别，服务端异常和非标准状态码记录为Info级别，由调用方按需记录错误
+func DefaultClientCodeToLevel(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.ResourceExhausted, codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return zapcore.DebugLevel
+	}
+
+	return zapcore.InfoLevel
+}
+
+// DefaultDurationToField 调用耗时映射日志字段的默认实现，单位为毫秒
+func DefaultDurationToField(duration time.Duration) zap.Field {
+	return zap.Float32(DurationKey, float32(duration.Nanoseconds()/1000)/1000)
+}
+
+// jsonpbMarshaler 基于protojson的protobuf消息序列化器
+type jsonpbMarshaler struct {
+	protojson.MarshalOptions
+}
+
+// Marshal 序列化protobuf消息
+func (m *jsonpbMarshaler) Marshal(out io.Writer, pb proto.Message) error {
+	b, err := m.MarshalOptions.Marshal(pb)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(b)
+	return err
+}
@@ -0,0 +1,65 @@
+package xgrpc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"cxqi/common/errcode"
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+// RecoveryUnaryServerInterceptor 服务端一元恐慌捕获拦截器，记录堆栈并将恐慌转换为意外错误，
+// 可选的恐慌捕获处理返回的业务错误将替代意外错误
+func RecoveryUnaryServerInterceptor(f ...logging.RecoveryHandlerContextFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverFrom(ctx, info.FullMethod, p, f...)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamServerInterceptor 服务端流恐慌捕获拦截器，记录堆栈并将恐慌转换为意外错误，
+// 可选的恐慌捕获处理返回的业务错误将替代意外错误
+func RecoveryStreamServerInterceptor(f ...logging.RecoveryHandlerContextFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverFrom(ss.Context(), info.FullMethod, p, f...)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recoverFrom 记录恐慌日志并转换为grpc状态错误
+func recoverFrom(ctx context.Context, fullMethod string, p interface{}, f ...logging.RecoveryHandlerContextFunc) error {
+	xzap.Ctx(ctx).Error("grpc server panic recovered",
+		zap.String(MethodKey, fullMethod),
+		zap.Any("panic", p),
+		zap.String("stack", string(debug.Stack())),
+	)
+
+	var err error
+	if len(f) > 0 && f[0] != nil {
+		err = f[0](ctx, p)
+	}
+	if err == nil {
+		err = errcode.ErrUnexpected
+	}
+
+	if _, ok := status.FromError(err); ok && !errcode.IsErr(err) {
+		return err
+	}
+
+	return errcode.ParseErr(err).Localize(errcode.LocaleFromContext(ctx)).GRPCStatus().Err()
+}
@@ -0,0 +1,45 @@
+package xgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"cxqi/common/errcode"
+)
+
+func TestRecoveryUnaryServerInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("something wrong")
+	}
+
+	_, err := RecoveryUnaryServerInterceptor()(context.Background(), nil, info, handler)
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, s.Code())
+	assert.Equal(t, errcode.ErrUnexpected.Error(), s.Message())
+	assert.Same(t, errcode.ErrUnexpected, errcode.ParseErr(err))
+
+	var recovered interface{}
+	_, err = RecoveryUnaryServerInterceptor(func(ctx context.Context, p interface{}) error {
+		recovered = p
+		return errcode.ErrInvalidParams
+	})(context.Background(), nil, info, handler)
+	assert.Equal(t, "something wrong", recovered)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Same(t, errcode.ErrInvalidParams, errcode.ParseErr(err))
+
+	entries := logs.FilterMessage("grpc server panic recovered").AllUntimed()
+	require.Len(t, entries, 2)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "something wrong", fields["panic"])
+	assert.Contains(t, fields["stack"], "runtime/debug.Stack")
+}
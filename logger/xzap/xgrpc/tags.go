@@ -0,0 +1,190 @@
+package xgrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"cxqi/common/jwt"
+	logging "cxqi/common/logger"
+)
+
+const (
+	// RequestFieldPrefix 请求字段tags前缀
+	RequestFieldPrefix = "grpc.request."
+
+	headerForwardedFor = "x-forwarded-for"
+	headerRealIP       = "x-real-ip"
+)
+
+// TagsUnaryServerInterceptor 服务端一元tags拦截器，为每个请求新建tags并提取用户id、链路追踪id、客户端IP和请求字段
+func TagsUnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = newTagsContext(ctx)
+		if o.extractor != nil {
+			setTags(logging.Extract(ctx), o.extractor(info.FullMethod, req))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// TagsStreamServerInterceptor 服务端流tags拦截器，为每个请求新建tags并提取用户id、链路追踪id、客户端IP，
+// 请求字段从首个接收的消息中提取
+func TagsStreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateServerOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wss := newWrappedServerStream(ss)
+		wss.WrappedContext = newTagsContext(wss.WrappedContext)
+		if o.extractor == nil {
+			return handler(srv, wss)
+		}
+
+		return handler(srv, &tagsServerStream{wrappedServerStream: wss, fullMethod: info.FullMethod, extractor: o.extractor})
+	}
+}
+
+// ProtoFieldExtractor 提取protobuf请求消息中指定的已设置的顶层标量字段
+func ProtoFieldExtractor(names ...string) RequestFieldExtractorFunc {
+	return func(fullMethod string, req interface{}) map[string]interface{} {
+		pb, ok := req.(proto.Message)
+		if !ok || len(names) == 0 {
+			return nil
+		}
+
+		m := pb.ProtoReflect()
+		fds := m.Descriptor().Fields()
+		values := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			fd := fds.ByName(protoreflect.Name(name))
+			if fd == nil || fd.IsList() || fd.IsMap() || fd.Message() != nil || !m.Has(fd) {
+				continue
+			}
+
+			v := m.Get(fd)
+			if fd.Enum() != nil {
+				if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+					values[RequestFieldPrefix+name] = string(ev.Name())
+					continue
+				}
+			}
+			values[RequestFieldPrefix+name] = v.Interface()
+		}
+
+		return values
+	}
+}
+
+// newTagsContext 新建或复用上下文中的tags，并设置用户id、链路追踪id和客户端IP
+func newTagsContext(ctx context.Context) context.Context {
+	tags := logging.Extract(ctx)
+	if tags == logging.NoopTags {
+		tags = logging.NewTags()
+		ctx = logging.SetInContext(ctx, tags)
+	}
+
+	if userId := userIdFromContext(ctx); userId > 0 {
+		tags.Set(logging.TagUserId, userId)
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		tags.Set(logging.TagTraceId, spanCtx.TraceID().String())
+	}
+	if ip := clientIPFromContext(ctx); ip != "" {
+		tags.Set(logging.TagClientIP, ip)
+	}
+
+	return ctx
+}
+
+// userIdFromContext 从上下文或grpc metadata中获取用户id
+func userIdFromContext(ctx context.Context) int64 {
+	if token, ok := jwt.FromContext(ctx); ok {
+		return token.UserId
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if token, ok := jwt.FromMD(md); ok {
+		return token.UserId
+	}
+
+	return 0
+}
+
+// clientIPFromContext 从grpc metadata或对端地址中获取客户端IP
+func clientIPFromContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(headerForwardedFor); len(values) > 0 {
+		if ip := strings.TrimSpace(strings.Split(values[0], ",")[0]); ip != "" {
+			return ip
+		}
+	}
+	if values := md.Get(headerRealIP); len(values) > 0 {
+		if ip := strings.TrimSpace(values[0]); ip != "" {
+			return ip
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if ip, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return ip
+		}
+		return p.Addr.String()
+	}
+
+	return ""
+}
+
+// setTags 批量设置tags
+func setTags(tags logging.Tags, values map[string]interface{}) {
+	for k, v := range values {
+		tags.Set(k, v)
+	}
+}
+
+// tagsServerStream 从首个接收的消息中提取请求字段的服务端流对象
+type tagsServerStream struct {
+	*wrappedServerStream
+	fullMethod string
+	extractor  RequestFieldExtractorFunc
+	extracted  bool
+}
+
+// RecvMsg 接收消息，首次接收时提取请求字段
+func (t *tagsServerStream) RecvMsg(m interface{}) error {
+	err := t.wrappedServerStream.RecvMsg(m)
+	if err == nil && !t.extracted {
+		t.extracted = true
+		setTags(logging.Extract(t.Context()), t.extractor(t.fullMethod, m))
+	}
+
+	return err
+}
+
+// wrappedServerStream 包装后的服务端流对象
+type wrappedServerStream struct {
+	grpc.ServerStream
+	WrappedContext context.Context
+}
+
+// newWrappedServerStream 新建包装后的服务端流对象
+func newWrappedServerStream(ss grpc.ServerStream) *wrappedServerStream {
+	if existing, ok := ss.(*wrappedServerStream); ok {
+		return existing
+	}
+	return &wrappedServerStream{ServerStream: ss, WrappedContext: ss.Context()}
+}
+
+// Context 返回包装后的服务端流对象的上下文信息
+func (w *wrappedServerStream) Context() context.Context {
+	return w.WrappedContext
+}
@@ -0,0 +1,59 @@
+package xgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+
+	"cxqi/common/jwt"
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+func TestTagsUnaryServerInterceptor(t *testing.T) {
+	logs := observe(t)
+
+	token := &jwt.Token{UserId: 1000}
+	var pairs []string
+	token.Visit(func(key, val string) bool {
+		pairs = append(pairs, key, val)
+		return true
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 8080}})
+
+	interceptor := TagsUnaryServerInterceptor(WithRequestFields("name", "syntax", "fields", "unknown"))
+	req := &typepb.Type{Name: "test", Syntax: typepb.Syntax_SYNTAX_PROTO3, Fields: []*typepb.Field{{Name: "f"}}}
+	_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			xzap.WithContext(ctx).Infof("handle request")
+			return nil, nil
+		})
+	require.NoError(t, err)
+
+	entries := logs.FilterMessage("handle request").AllUntimed()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, int64(1000), fields[logging.TagUserId])
+	assert.Equal(t, "10.0.0.1", fields[logging.TagClientIP])
+	assert.Equal(t, "test", fields[RequestFieldPrefix+"name"])
+	assert.Equal(t, "SYNTAX_PROTO3", fields[RequestFieldPrefix+"syntax"])
+	assert.NotContains(t, fields, RequestFieldPrefix+"fields")
+	assert.NotContains(t, fields, RequestFieldPrefix+"unknown")
+}
+
+func TestProtoFieldExtractor(t *testing.T) {
+	extractor := ProtoFieldExtractor("name")
+	assert.Nil(t, extractor("/test.Service/Method", "not proto"))
+	assert.Empty(t, extractor("/test.Service/Method", &structpb.Struct{}))
+	assert.Equal(t, map[string]interface{}{RequestFieldPrefix + "name": "test"},
+		extractor("/test.Service/Method", &typepb.Type{Name: "test"}))
+}
@@ -0,0 +1,298 @@
+package xhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"cxqi/common/jwt"
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/redact"
+	"cxqi/common/logger/xzap"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+const (
+	// DefaultSlowThreshold 默认慢请求阈值
+	DefaultSlowThreshold = 500 * time.Millisecond
+	// DefaultBodyHalfShowLen 默认请求体和响应体首尾各保留的长度
+	DefaultBodyHalfShowLen = 512
+
+	accessMsg = "http access"
+)
+
+// AccessOption 访问日志中间件可选配置
+type AccessOption func(*accessOptions)
+
+// accessOptions 访问日志中间件配置
+type accessOptions struct {
+	skipPaths     []string
+	skipper       func(c *gin.Context) bool
+	requestBody   bool
+	responseBody  bool
+	halfShowLen   int
+	slowThreshold time.Duration
+	redact        bool
+}
+
+// WithSkipPaths 不记录访问日志的路径，匹配请求路径或路由模板，以*结尾时按前缀匹配
+func WithSkipPaths(paths ...string) AccessOption {
+	return func(o *accessOptions) {
+		o.skipPaths = append(o.skipPaths, paths...)
+	}
+}
+
+// WithSkipper 自定义是否跳过记录访问日志
+func WithSkipper(f func(c *gin.Context) bool) AccessOption {
+	return func(o *accessOptions) {
+		o.skipper = f
+	}
+}
+
+// WithRequestBody 记录请求体，超长时截断
+func WithRequestBody() AccessOption {
+	return func(o *accessOptions) {
+		o.requestBody = true
+	}
+}
+
+// WithResponseBody 记录响应体，超长时截断
+func WithResponseBody() AccessOption {
+	return func(o *accessOptions) {
+		o.responseBody = true
+	}
+}
+
+// WithBodyHalfShowLen 自定义请求体和响应体截断时首尾各保留的长度
+func WithBodyHalfShowLen(n int) AccessOption {
+	return func(o *accessOptions) {
+		o.halfShowLen = n
+	}
+}
+
+// WithSlowThreshold 自定义慢请求阈值，为0时不标记慢请求
+func WithSlowThreshold(d time.Duration) AccessOption {
+	return func(o *accessOptions) {
+		o.slowThreshold = d
+	}
+}
+
+// WithRedact 按脱敏规则记录请求体和响应体，与日志配置的redact保持一致
+func WithRedact(enabled bool) AccessOption {
+	return func(o *accessOptions) {
+		o.redact = enabled
+	}
+}
+
+// evaluateAccessOptions 计算访问日志中间件配置
+func evaluateAccessOptions(opts []AccessOption) *accessOptions {
+	o := &accessOptions{
+		halfShowLen:   DefaultBodyHalfShowLen,
+		slowThreshold: DefaultSlowThreshold,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// skip 判断是否跳过记录访问日志
+func (o *accessOptions) skip(c *gin.Context) bool {
+	if o.skipper != nil && o.skipper(c) {
+		return true
+	}
+
+	path, route := c.Request.URL.Path, c.FullPath()
+	for _, p := range o.skipPaths {
+		if prefix := strings.TrimSuffix(p, "*"); prefix != p {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path || p == route {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AccessLog 访问日志中间件，每个请求记录一条访问日志，
+// 服务端错误以error级别记录，客户端错误和慢请求以warn级别记录
+func AccessLog(opts ...AccessOption) gin.HandlerFunc {
+	o := evaluateAccessOptions(opts)
+
+	return func(c *gin.Context) {
+		if o.skip(c) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var reqBody, respBody *bodyBuffer
+		if o.requestBody && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			reqBody = &bodyBuffer{limit: o.halfShowLen}
+			c.Request.Body = &teeReadCloser{Reader: io.TeeReader(c.Request.Body, reqBody), Closer: c.Request.Body}
+		}
+		if o.responseBody {
+			respBody = &bodyBuffer{limit: o.halfShowLen}
+			c.Writer = &bodyWriter{ResponseWriter: c.Writer, body: respBody}
+		}
+
+		c.Next()
+
+		duration := time.Since(start)
+		status := c.Writer.Status()
+		slow := o.slowThreshold > 0 && duration > o.slowThreshold
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zapcore.ErrorLevel
+		case status >= http.StatusBadRequest || slow:
+			level = zapcore.WarnLevel
+		}
+
+		ctx := c.Request.Context()
+		ce := xzap.Ctx(ctx).Check(level, accessMsg)
+		if ce == nil {
+			return
+		}
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		fields := []zap.Field{
+			zap.String(MethodKey, c.Request.Method),
+			zap.String(PathKey, c.Request.URL.Path),
+			zap.String(RouteKey, c.FullPath()),
+			zap.Int(StatusKey, status),
+			zap.Float64(DurationKey, float64(duration.Nanoseconds())/1e6),
+			zap.Int(BytesKey, size),
+		}
+		if code, err := strconv.Atoi(c.Writer.Header().Get(xhttpUtil.HeaderGWErrorCode)); err == nil {
+			fields = append(fields, zap.Int(CodeKey, code))
+		}
+		if slow {
+			fields = append(fields, zap.Bool(SlowKey, true))
+		}
+
+		// 未注册请求tags中间件时补充客户端IP和用户id
+		tags := logging.Extract(ctx)
+		if !tags.Has(logging.TagClientIP) {
+			fields = append(fields, zap.String(logging.TagClientIP, xhttpUtil.GetClientIP(c.Request)))
+		}
+		if token, ok := jwt.FromContext(ctx); ok && token.UserId > 0 && !tags.Has(logging.TagUserId) {
+			fields = append(fields, zap.Int64(logging.TagUserId, token.UserId))
+		}
+
+		if reqBody != nil {
+			fields = append(fields, zap.String(RequestBodyKey, reqBody.content(o.redact)))
+		}
+		if respBody != nil {
+			fields = append(fields, zap.String(ResponseBodyKey, respBody.content(o.redact)))
+		}
+
+		ce.Write(fields...)
+	}
+}
+
+// bodyBuffer 只保留首尾各limit字节的请求体或响应体缓冲区
+type bodyBuffer struct {
+	limit int
+	head  []byte
+	tail  []byte
+	total int
+}
+
+// Write 写入数据，超出部分只保留末尾limit字节
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+
+	rest := p
+	if n := b.limit - len(b.head); n > 0 {
+		if n > len(rest) {
+			n = len(rest)
+		}
+		b.head = append(b.head, rest[:n]...)
+		rest = rest[n:]
+	}
+
+	b.tail = append(b.tail, rest...)
+	if over := len(b.tail) - b.limit; over > 0 {
+		b.tail = append(b.tail[:0], b.tail[over:]...)
+	}
+
+	return len(p), nil
+}
+
+// truncated 判断是否被截断
+func (b *bodyBuffer) truncated() bool {
+	return b.total > len(b.head)+len(b.tail)
+}
+
+// content 获取记录的内容，被截断时在首尾之间插入省略号，开启脱敏时首尾分别按json片段脱敏
+func (b *bodyBuffer) content(redacted bool) string {
+	if !b.truncated() {
+		body := append(append([]byte(nil), b.head...), b.tail...)
+		if redacted {
+			body = redact.JSON(body)
+		}
+		return string(body)
+	}
+	if !redacted {
+		return string(b.head) + " ...... " + string(b.tail)
+	}
+
+	tail := b.tail
+	if isJSON(b.head) {
+		// 末尾片段开头的值可能与其键名一起被截断，丢弃到下一个键为止，避免残缺的敏感字段值未被脱敏
+		if i := bytes.Index(tail, []byte(`,"`)); i >= 0 {
+			tail = tail[i+1:]
+		} else {
+			tail = nil
+		}
+	}
+
+	return redact.JSONFragment(string(b.head)) + " ...... " + redact.JSONFragment(string(tail))
+}
+
+// isJSON 根据开头字符判断内容是否为json对象或数组
+func isJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && (b[0] == '{' || b[0] == '[')
+}
+
+// teeReadCloser 读取时同时写入缓冲区的请求体
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter 写入时同时写入缓冲区的响应
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bodyBuffer
+}
+
+// Write 写入响应体
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	_, _ = w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// WriteString 写入字符串响应体
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	_, _ = w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
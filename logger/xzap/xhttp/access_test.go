@@ -0,0 +1,108 @@
+package xhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap/xzaptest"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+func TestAccessLog(t *testing.T) {
+	logs := xzaptest.Replace(t)
+
+	r := gin.New()
+	r.Use(AccessLog(WithSkipPaths("/health", "/debug/*"), WithRequestBody(), WithResponseBody(),
+		WithBodyHalfShowLen(16), WithSlowThreshold(50*time.Millisecond), WithRedact(true)))
+	r.POST("/users/:id", func(c *gin.Context) {
+		_, _ = c.GetRawData()
+		xhttpUtil.Error(c, errcode.ErrInvalidParams)
+	})
+	r.GET("/health", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/debug/pprof", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(60 * time.Millisecond)
+		c.String(http.StatusOK, strings.Repeat("a", 100))
+	})
+	r.GET("/panic", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"name":"tom","password":"123"}`))
+	req.Header.Set("X-Real-Ip", "10.0.0.1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/debug/pprof", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	require.Equal(t, 3, logs.Len())
+	entries := logs.All()
+
+	e := entries[0]
+	assert.Equal(t, zapcore.InfoLevel, e.Level)
+	assert.Equal(t, accessMsg, e.Message)
+	assert.Equal(t, http.MethodPost, e.Fields[MethodKey])
+	assert.Equal(t, "/users/1", e.Fields[PathKey])
+	assert.Equal(t, "/users/:id", e.Fields[RouteKey])
+	assert.Equal(t, int64(http.StatusOK), e.Fields[StatusKey])
+	assert.Equal(t, int64(errcode.ErrInvalidParams.Code()), e.Fields[CodeKey])
+	assert.Equal(t, "10.0.0.1", e.Fields["client_ip"])
+	assert.Equal(t, `{"name":"tom","password":"******"}`, e.Fields[RequestBodyKey])
+	assert.Contains(t, e.Fields[ResponseBodyKey], " ...... ")
+
+	e = entries[1]
+	assert.Equal(t, zapcore.WarnLevel, e.Level)
+	assert.Equal(t, true, e.Fields[SlowKey])
+	assert.Equal(t, int64(100), e.Fields[BytesKey])
+	assert.Equal(t, strings.Repeat("a", 16)+" ...... "+strings.Repeat("a", 16), e.Fields[ResponseBodyKey])
+
+	e = entries[2]
+	assert.Equal(t, zapcore.ErrorLevel, e.Level)
+	assert.Equal(t, int64(0), e.Fields[BytesKey])
+}
+
+func TestAccessLog_RedactDisabled(t *testing.T) {
+	logs := xzaptest.Replace(t)
+
+	r := gin.New()
+	r.Use(AccessLog(WithRequestBody()))
+	r.POST("/login", func(c *gin.Context) {
+		_, _ = c.GetRawData()
+		c.Status(http.StatusOK)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"password":"123"}`)))
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, `{"password":"123"}`, logs.All()[0].Fields[RequestBodyKey])
+}
+
+func TestAccessLog_TruncatedBodyRedacted(t *testing.T) {
+	logs := xzaptest.Replace(t)
+
+	r := gin.New()
+	r.Use(AccessLog(WithRequestBody(), WithBodyHalfShowLen(40), WithRedact(true)))
+	r.POST("/login", func(c *gin.Context) {
+		_, _ = c.GetRawData()
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"password":"secret-head-123","remark":"` + strings.Repeat("x", 100) +
+		`","token":"secret-tail-456","name":"tom"}`
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+
+	require.Equal(t, 1, logs.Len())
+	reqBody := logs.All()[0].Fields[RequestBodyKey].(string)
+	assert.Contains(t, reqBody, " ...... ")
+	assert.Contains(t, reqBody, `"password":"******"`)
+	assert.Contains(t, reqBody, `"token":"******"`)
+	assert.Contains(t, reqBody, `"name":"tom"}`)
+	assert.NotContains(t, reqBody, "secret")
+}
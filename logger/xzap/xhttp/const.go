@@ -1 +1,24 @@
 package xhttp
+
+const (
+	// MethodKey http请求方法字段
+	MethodKey = "http.method"
+	// PathKey http请求路径字段
+	PathKey = "http.path"
+	// RouteKey http路由模板字段
+	RouteKey = "http.route"
+	// StatusKey http状态码字段
+	StatusKey = "http.status"
+	// CodeKey 业务状态码字段，取自X-GW-Error-Code响应头
+	CodeKey = "http.code"
+	// DurationKey 请求耗时字段（毫秒）
+	DurationKey = "http.time_ms"
+	// BytesKey 响应体字节数字段
+	BytesKey = "http.bytes"
+	// SlowKey 慢请求标记字段
+	SlowKey = "http.slow"
+	// RequestBodyKey 请求体字段
+	RequestBodyKey = "http.request.body"
+	// ResponseBodyKey 响应体字段
+	ResponseBodyKey = "http.response.body"
+)
@@ -0,0 +1,44 @@
+package xhttp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+// LevelRequest 日志级别设置请求
+type LevelRequest struct {
+	Module string `json:"module"` // 模块名称，为空时设置根模块
+	Level  string `json:"level"`  // 日志级别，为空时取消模块的级别覆盖
+	TTL    string `json:"ttl"`    // 过期时间（如10m），过期后恢复原级别，为空时不过期
+}
+
+// LevelHandler 日志级别管理处理器，GET请求获取全部模块的日志级别，
+// 其他请求通过json请求体设置模块的日志级别，返回设置后全部模块的日志级别
+func LevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			var req LevelRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				xhttpUtil.Error(c, errcode.ErrInvalidParams)
+				return
+			}
+
+			if err := xzap.ChangeLevel(req.Module, req.Level, req.TTL); err != nil {
+				xhttpUtil.Error(c, errcode.NewCustomErr(err.Error()))
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, &xhttpUtil.Reponse{
+			TraceId: xhttpUtil.GetTraceId(c.Request.Context()),
+			Code:    errcode.CodeOK,
+			Msg:     errcode.MsgOK,
+			Data:    xzap.Levels(),
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package xhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer xzap.ResetLevel("http_module")
+
+	r := gin.New()
+	r.Any("/log/level", LevelHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/level",
+		strings.NewReader(`{"module":"http_module","level":"debug","ttl":"10m"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "debug", xzap.GetLevel("http_module"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Code uint32            `json:"code"`
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, uint32(errcode.CodeOK), resp.Code)
+	assert.Equal(t, "debug", resp.Data["http_module"])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/level",
+		strings.NewReader(`{"module":"http_module","level":"unknown"}`)))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, uint32(errcode.CodeCustom), resp.Code)
+}
@@ -0,0 +1,84 @@
+package xhttp
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"cxqi/common/errcode"
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+// Recovery 恐慌捕获中间件，记录堆栈并将恐慌转换为意外错误响应，
+// 可选的恐慌捕获处理返回的业务错误将替代意外错误
+func Recovery(f ...logging.RecoveryHandlerContextFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				ctx := c.Request.Context()
+				brokenPipe := isBrokenPipe(p)
+
+				xzap.Ctx(ctx).Error("http server panic recovered",
+					zap.String(MethodKey, c.Request.Method),
+					zap.String(PathKey, c.Request.URL.Path),
+					zap.Any("panic", p),
+					zap.String("stack", string(debug.Stack())),
+				)
+
+				// 连接已断开，无法写入响应
+				if brokenPipe || c.Writer.Written() {
+					c.Abort()
+					return
+				}
+
+				var err error
+				if len(f) > 0 && f[0] != nil {
+					err = f[0](ctx, p)
+				}
+				if err == nil {
+					err = errcode.ErrUnexpected
+				}
+
+				e := errcode.ParseErr(err).Localize(errcode.LocaleFromRequest(c.Request))
+				xhttpUtil.WriteHeader(c.Writer, e)
+				c.AbortWithStatusJSON(e.HTTPCode(), &xhttpUtil.Reponse{
+					TraceId: xhttpUtil.GetTraceId(ctx),
+					Code:    e.Code(),
+					Msg:     e.Error(),
+					Data:    nil,
+					Details: e.Details(),
+				})
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe 判断恐慌是否由客户端断开连接引起
+func isBrokenPipe(p interface{}) bool {
+	err, ok := p.(error)
+	if !ok {
+		return false
+	}
+
+	var ne *net.OpError
+	if !errors.As(err, &ne) {
+		return false
+	}
+
+	var se *os.SyscallError
+	if errors.As(ne, &se) {
+		msg := strings.ToLower(se.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package xhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func observe(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.Cleanup(xzap.ReplaceLogger(zap.New(core)))
+	return logs
+}
+
+func TestRecovery(t *testing.T) {
+	logs := observe(t)
+
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/panic", func(c *gin.Context) {
+		panic("something wrong")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "7777", w.Header().Get(xhttpUtil.HeaderGWErrorCode))
+
+	var resp xhttpUtil.Reponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, errcode.ErrUnexpected.Code(), resp.Code)
+	assert.Equal(t, errcode.ErrUnexpected.Error(), resp.Msg)
+
+	entries := logs.FilterMessage("http server panic recovered").AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/panic", entries[0].ContextMap()[PathKey])
+	assert.Equal(t, "something wrong", entries[0].ContextMap()["panic"])
+}
+
+func TestRecovery_Handler(t *testing.T) {
+	observe(t)
+
+	r := gin.New()
+	r.Use(Recovery(func(ctx context.Context, p interface{}) error {
+		return errcode.ErrInvalidParams
+	}))
+	r.GET("/panic", func(c *gin.Context) {
+		panic("something wrong")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp xhttpUtil.Reponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, errcode.ErrInvalidParams.Code(), resp.Code)
+}
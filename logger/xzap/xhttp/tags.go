@@ -0,0 +1,35 @@
+package xhttp
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"cxqi/common/jwt"
+	logging "cxqi/common/logger"
+	xhttpUtil "cxqi/common/xhttp"
+)
+
+// Tags 请求tags中间件，为每个请求新建tags并提取用户id、链路追踪id和客户端IP，
+// 需要注册在设置令牌数据的鉴权中间件之后才能提取到用户id
+func Tags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tags := logging.Extract(ctx)
+		if tags == logging.NoopTags {
+			tags = logging.NewTags()
+			ctx = logging.SetInContext(ctx, tags)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		if token, ok := jwt.FromContext(ctx); ok && token.UserId > 0 {
+			tags.Set(logging.TagUserId, token.UserId)
+		}
+		if traceId := xhttpUtil.GetTraceId(ctx); traceId != "" {
+			tags.Set(logging.TagTraceId, traceId)
+		}
+		if ip := xhttpUtil.GetClientIP(c.Request); ip != "" {
+			tags.Set(logging.TagClientIP, ip)
+		}
+
+		c.Next()
+	}
+}
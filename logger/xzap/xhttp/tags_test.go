@@ -0,0 +1,37 @@
+package xhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cxqi/common/jwt"
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+func TestTags(t *testing.T) {
+	logs := observe(t)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(jwt.WithToken(c.Request.Context(), &jwt.Token{UserId: 1000}))
+	}, Tags())
+	r.GET("/tags", func(c *gin.Context) {
+		xzap.WithContext(c.Request.Context()).Infof("handle request")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tags", nil)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterMessage("handle request").AllUntimed()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, int64(1000), fields[logging.TagUserId])
+	assert.Equal(t, "10.0.0.1", fields[logging.TagClientIP])
+}
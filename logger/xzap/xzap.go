@@ -0,0 +1,370 @@
+package xzap
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap/rotate"
+)
+
+const (
+	// ModeConsole 日志模式：控制台
+	ModeConsole = "console"
+	// ModeFile 日志模式：文件
+	ModeFile = "file"
+
+	// DefaultPath 默认日志文件目录
+	DefaultPath = "logs"
+	// DefaultLevel 默认日志级别
+	DefaultLevel = "info"
+
+	// ServiceKey 服务名称字段
+	ServiceKey = "service"
+	// TraceIdKey 链路追踪id字段
+	TraceIdKey = logging.TagTraceId
+	// SpanIdKey 链路跨度id字段
+	SpanIdKey = "span_id"
+
+	// SlowLoggerName 慢日志记录器名称，文件模式下写入slow.log
+	SlowLoggerName = "slow"
+
+	accessFilename = "access.log"
+	errorFilename  = "error.log"
+	slowFilename   = "slow.log"
+)
+
+var (
+	// base 全局基础日志记录器
+	base atomic.Pointer[zap.Logger]
+
+	// closers 全局日志记录器打开的日志文件和异步写入器
+	closersMu sync.Mutex
+	closers   []io.Closer
+)
+
+func init() {
+	MustInit(logging.LogConf{Mode: ModeConsole, Level: DefaultLevel})
+}
+
+// NewLogger 根据配置新建zap日志记录器，日志级别固定为配置的级别，
+// 返回的关闭函数刷新缓冲日志并关闭日志文件和异步写入器，不再使用日志记录器时必须调用
+func NewLogger(c logging.LogConf, opts ...zap.Option) (*zap.Logger, func() error, error) {
+	level, err := ParseLevel(c.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	core, files, err := newCore(c, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	core = newSamplingCore(c.Sampling, core)
+	l := newLogger(c, core, opts...)
+
+	var once sync.Once
+	closeFn := func() error {
+		err := l.Sync()
+		once.Do(func() {
+			for _, f := range files {
+				if cerr := f.Close(); cerr != nil {
+					err = cerr
+				}
+			}
+		})
+		return err
+	}
+
+	return l, closeFn, nil
+}
+
+// Init 根据配置初始化全局日志记录器，日志级别可通过SetLevel在运行时调整
+func Init(c logging.LogConf, opts ...zap.Option) error {
+	level, err := ParseLevel(c.Level)
+	if err != nil {
+		return err
+	}
+
+	core, files, err := newCore(c, levels)
+	if err != nil {
+		return err
+	}
+	core = newSamplingCore(c.Sampling, core)
+
+	levels.set(RootModule, &level, 0)
+	l := newLogger(c, &moduleCore{Core: core, module: RootModule}, opts...)
+
+	if old := base.Swap(l); old != nil {
+		_ = old.Sync()
+	}
+
+	closersMu.Lock()
+	old := closers
+	closers = files
+	closersMu.Unlock()
+	for _, f := range old {
+		_ = f.Close()
+	}
+
+	return nil
+}
+
+// MustInit 根据配置初始化全局日志记录器
+func MustInit(c logging.LogConf, opts ...zap.Option) {
+	if err := Init(c, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// ReplaceLogger 替换全局基础日志记录器，返回恢复原日志记录器的函数
+func ReplaceLogger(l *zap.Logger) func() {
+	prev := base.Swap(l)
+	return func() { ReplaceLogger(prev) }
+}
+
+// L 获取全局基础日志记录器
+func L() *zap.Logger {
+	return base.Load()
+}
+
+// Ctx 获取携带上下文字段（链路追踪id和请求tags）的zap日志记录器
+func Ctx(ctx context.Context) *zap.Logger {
+	l := L()
+	if ctx == nil {
+		return l
+	}
+
+	if fields := ContextFields(ctx); len(fields) > 0 {
+		return l.With(fields...)
+	}
+
+	return l
+}
+
+// WithContext 获取携带上下文字段（链路追踪id和请求tags）的日志记录器
+func WithContext(ctx context.Context) logging.Logger {
+	return Wrap(Ctx(ctx))
+}
+
+// SlowWithContext 获取指定模块携带上下文字段的慢日志记录器，module为空时使用根模块
+func SlowWithContext(ctx context.Context, module string) logging.Logger {
+	l := L()
+	if module != "" && module != RootModule {
+		l = Module(module)
+	}
+	l = l.Named(SlowLoggerName)
+
+	if ctx != nil {
+		if fields := ContextFields(ctx); len(fields) > 0 {
+			l = l.With(fields...)
+		}
+	}
+
+	return Wrap(l)
+}
+
+// Wrap 将zap日志记录器包装为日志记录器
+func Wrap(l *zap.Logger) logging.Logger {
+	return &sugaredLogger{SugaredLogger: l.Sugar()}
+}
+
+// ContextFields 获取上下文中的链路追踪id和请求tags字段
+func ContextFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+
+	tags := logging.Extract(ctx)
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if spanCtx.HasTraceID() && !tags.Has(TraceIdKey) {
+		fields = append(fields, zap.String(TraceIdKey, spanCtx.TraceID().String()))
+	}
+	if spanCtx.HasSpanID() {
+		fields = append(fields, zap.String(SpanIdKey, spanCtx.SpanID().String()))
+	}
+
+	for k, v := range tags.Values() {
+		fields = append(fields, zap.Any(k, v))
+	}
+
+	return fields
+}
+
+// sugaredLogger 基于zap.SugaredLogger实现的日志记录器
+type sugaredLogger struct {
+	*zap.SugaredLogger
+}
+
+// With 返回附带键值对字段的日志记录器
+func (l *sugaredLogger) With(keysAndValues ...interface{}) logging.Logger {
+	return &sugaredLogger{SugaredLogger: l.SugaredLogger.With(keysAndValues...)}
+}
+
+// Sync 刷新全局日志记录器缓冲区
+func Sync() error {
+	return L().Sync()
+}
+
+// Close 刷新全局日志记录器缓冲区并关闭日志文件和异步写入器，应在程序退出前调用
+func Close() error {
+	err := Sync()
+
+	closersMu.Lock()
+	old := closers
+	closers = nil
+	closersMu.Unlock()
+
+	for _, f := range old {
+		if cerr := f.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// ParseLevel 解析日志级别，为空时返回默认级别
+func ParseLevel(level string) (zapcore.Level, error) {
+	if level == "" {
+		level = DefaultLevel
+	}
+
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return l, errors.Errorf("xzap: illegal log level: %s", level)
+	}
+
+	return l, nil
+}
+
+// newLogger 新建zap日志记录器
+func newLogger(c logging.LogConf, core zapcore.Core, opts ...zap.Option) *zap.Logger {
+	zopts := []zap.Option{zap.AddCaller(), zap.AddStacktrace(zapcore.DPanicLevel)}
+	if c.ServiceName != "" {
+		zopts = append(zopts, zap.Fields(zap.String(ServiceKey, c.ServiceName)))
+	}
+
+	return zap.New(core, append(zopts, opts...)...)
+}
+
+// newCore 根据日志模式新建zap core，同时返回需要关闭的日志文件和异步写入器
+func newCore(c logging.LogConf, level zapcore.LevelEnabler) (zapcore.Core, []io.Closer, error) {
+	enc := zapcore.NewJSONEncoder(newEncoderConfig())
+	if c.Redact {
+		enc = &redactEncoder{Encoder: enc}
+	}
+
+	switch c.Mode {
+	case "", ModeConsole:
+		if c.Async.Enabled {
+			w := NewAsyncWriter(zapcore.Lock(os.Stdout), c.Async)
+			return zapcore.NewCore(enc, w, level), []io.Closer{w}, nil
+		}
+		return zapcore.NewCore(enc, zapcore.Lock(os.Stdout), level), nil, nil
+	case ModeFile:
+		path := c.Path
+		if path == "" {
+			path = DefaultPath
+		}
+
+		var files []io.Closer
+		newFileCore := func(filename string, level zapcore.LevelEnabler, slow bool) (zapcore.Core, error) {
+			w, err := rotate.NewWriter(rotate.Config{
+				Filename: filepath.Join(path, filename),
+				MaxSize:  c.MaxSize,
+				KeepDays: c.KeepDays,
+				Compress: c.Compress,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			var ws zapcore.WriteSyncer = w
+			if c.Async.Enabled {
+				ws = NewAsyncWriter(w, c.Async)
+			}
+			files = append(files, ws.(io.Closer))
+
+			return &slowCore{Core: zapcore.NewCore(enc.Clone(), ws, level), slow: slow}, nil
+		}
+
+		errorLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= zapcore.ErrorLevel && level.Enabled(l)
+		})
+
+		var cores []zapcore.Core
+		for _, fc := range []struct {
+			filename string
+			level    zapcore.LevelEnabler
+			slow     bool
+		}{
+			{accessFilename, level, false},
+			{errorFilename, errorLevel, false},
+			{slowFilename, level, true},
+		} {
+			core, err := newFileCore(fc.filename, fc.level, fc.slow)
+			if err != nil {
+				for _, f := range files {
+					_ = f.Close()
+				}
+				return nil, nil, errors.WithMessage(err, "xzap: create log file err")
+			}
+			cores = append(cores, core)
+		}
+
+		return zapcore.NewTee(cores...), files, nil
+	default:
+		return nil, nil, errors.Errorf("xzap: illegal log mode: %s", c.Mode)
+	}
+}
+
+// slowCore 按日志记录器名称分流的zap core，slow为true时只写入慢日志，否则只写入非慢日志
+type slowCore struct {
+	zapcore.Core
+	slow bool
+}
+
+// With 返回附带字段的zap core
+func (c *slowCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slowCore{Core: c.Core.With(fields), slow: c.slow}
+}
+
+// Check 判断日志是否写入当前core
+func (c *slowCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if isSlow(ent.LoggerName) != c.slow {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// isSlow 判断日志记录器是否为慢日志记录器
+func isSlow(name string) bool {
+	return name == SlowLoggerName || strings.HasSuffix(name, "."+SlowLoggerName)
+}
+
+// newEncoderConfig 新建日志编码配置
+func newEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "ts",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.MillisDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+}
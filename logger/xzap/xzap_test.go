@@ -0,0 +1,132 @@
+package xzap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	logging "cxqi/common/logger"
+)
+
+func TestNewLogger(t *testing.T) {
+	_, _, err := NewLogger(logging.LogConf{Mode: "unknown"})
+	assert.EqualError(t, err, "xzap: illegal log mode: unknown")
+
+	_, _, err = NewLogger(logging.LogConf{Level: "unknown"})
+	assert.EqualError(t, err, "xzap: illegal log level: unknown")
+
+	dir := t.TempDir()
+	l, closeFn, err := NewLogger(logging.LogConf{ServiceName: "test", Mode: ModeFile, Path: dir, Level: "info"})
+	require.NoError(t, err)
+	defer closeFn()
+
+	l.Debug("debug test")
+	l.Info("info test")
+	l.Error("error test")
+	l.Named(SlowLoggerName).Warn("slow test")
+	require.NoError(t, l.Sync())
+
+	access, err := os.ReadFile(filepath.Join(dir, accessFilename))
+	require.NoError(t, err)
+	assert.NotContains(t, string(access), "debug test")
+	assert.Contains(t, string(access), "info test")
+	assert.Contains(t, string(access), "error test")
+	assert.Contains(t, string(access), `"service":"test"`)
+
+	errs, err := os.ReadFile(filepath.Join(dir, errorFilename))
+	require.NoError(t, err)
+	assert.NotContains(t, string(errs), "info test")
+	assert.Contains(t, string(errs), "error test")
+
+	slow, err := os.ReadFile(filepath.Join(dir, slowFilename))
+	require.NoError(t, err)
+	assert.NotContains(t, string(slow), "info test")
+	assert.Contains(t, string(slow), "slow test")
+	assert.NotContains(t, string(access), "slow test")
+}
+
+func TestNewLogger_Close(t *testing.T) {
+	dir := t.TempDir()
+	l, closeFn, err := NewLogger(logging.LogConf{Mode: ModeFile, Path: dir,
+		Async: logging.AsyncConf{Enabled: true, BufferSize: 1024}})
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		l.Info("async test")
+	}
+	require.NoError(t, closeFn())
+	require.NoError(t, closeFn())
+
+	access, err := os.ReadFile(filepath.Join(dir, accessFilename))
+	require.NoError(t, err)
+	assert.Equal(t, 100, strings.Count(string(access), "async test"))
+}
+
+func TestWithContext(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := base.Swap(zap.New(core))
+	defer base.Store(old)
+
+	traceId, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanId, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}))
+	ctx = logging.SetInContext(ctx, logging.NewTags().Set("user_id", int64(1000)))
+
+	WithContext(ctx).Infof("hello %s", "world")
+	WithContext(context.Background()).Warnf("no context")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "hello world", entries[0].Message)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, traceId.String(), fields[TraceIdKey])
+	assert.Equal(t, spanId.String(), fields[SpanIdKey])
+	assert.Equal(t, int64(1000), fields["user_id"])
+
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Empty(t, entries[1].Context)
+}
+
+func TestWithContext_Structured(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer ReplaceLogger(zap.New(core))()
+
+	l := WithContext(context.Background()).With("module", "test")
+	l.Infow("structured", "rows", 10, "sql", "select 1")
+	l.Errorw("structured error", "err", "something wrong")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"module": "test", "rows": int64(10), "sql": "select 1"}, entries[0].ContextMap())
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "test", entries[1].ContextMap()["module"])
+}
+
+func TestNewLogger_Redact(t *testing.T) {
+	dir := t.TempDir()
+	l, closeFn, err := NewLogger(logging.LogConf{Mode: ModeFile, Path: dir, Redact: true})
+	require.NoError(t, err)
+	defer closeFn()
+
+	l.With(zap.String("token", "abc")).Info("card 6222021234567891233",
+		zap.String("password", "123456"), zap.String("auth", "Bearer abc"))
+	require.NoError(t, l.Sync())
+
+	access, err := os.ReadFile(filepath.Join(dir, accessFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(access), `"msg":"card 622************1233"`)
+	assert.Contains(t, string(access), `"token":"******"`)
+	assert.Contains(t, string(access), `"password":"******"`)
+	assert.Contains(t, string(access), `"auth":"Bearer ******"`)
+}
@@ -0,0 +1,262 @@
+package xzaptest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+// Entry 捕获的日志条目，Tags为日志记录器附带的上下文字段（链路追踪id、请求tags和With字段），Fields为记录日志时传入的字段
+type Entry struct {
+	Level   zapcore.Level
+	Time    time.Time
+	Logger  string
+	Message string
+	Tags    map[string]interface{}
+	Fields  map[string]interface{}
+}
+
+// Field 获取字段值，优先获取记录日志时传入的字段
+func (e Entry) Field(key string) (interface{}, bool) {
+	if v, ok := e.Fields[key]; ok {
+		return v, true
+	}
+
+	v, ok := e.Tags[key]
+	return v, ok
+}
+
+// Logs 捕获的日志
+type Logs struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// Len 获取日志条数
+func (l *Logs) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.entries)
+}
+
+// All 获取全部日志
+func (l *Logs) All() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]Entry(nil), l.entries...)
+}
+
+// TakeAll 获取并清空全部日志
+func (l *Logs) TakeAll() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := l.entries
+	l.entries = nil
+	return entries
+}
+
+// Messages 获取全部日志消息
+func (l *Logs) Messages() []string {
+	entries := l.All()
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = e.Message
+	}
+
+	return messages
+}
+
+// Filter 过滤日志，返回满足条件的日志
+func (l *Logs) Filter(keep func(e Entry) bool) *Logs {
+	filtered := &Logs{}
+	for _, e := range l.All() {
+		if keep(e) {
+			filtered.entries = append(filtered.entries, e)
+		}
+	}
+
+	return filtered
+}
+
+// FilterLevel 过滤指定级别的日志
+func (l *Logs) FilterLevel(level zapcore.Level) *Logs {
+	return l.Filter(func(e Entry) bool { return e.Level == level })
+}
+
+// FilterLevelAtLeast 过滤不低于指定级别的日志
+func (l *Logs) FilterLevelAtLeast(level zapcore.Level) *Logs {
+	return l.Filter(func(e Entry) bool { return e.Level >= level })
+}
+
+// FilterMessage 过滤指定消息的日志
+func (l *Logs) FilterMessage(msg string) *Logs {
+	return l.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// FilterMessageContains 过滤消息包含指定内容的日志
+func (l *Logs) FilterMessageContains(s string) *Logs {
+	return l.Filter(func(e Entry) bool { return strings.Contains(e.Message, s) })
+}
+
+// FilterLogger 过滤指定日志记录器名称的日志，如xzap.SlowLoggerName
+func (l *Logs) FilterLogger(name string) *Logs {
+	return l.Filter(func(e Entry) bool { return e.Logger == name })
+}
+
+// FilterField 过滤包含指定字段的日志，value为nil时只判断字段是否存在
+func (l *Logs) FilterField(key string, value interface{}) *Logs {
+	return l.Filter(func(e Entry) bool {
+		v, ok := e.Field(key)
+		return ok && (value == nil || assert.ObjectsAreEqualValues(value, v))
+	})
+}
+
+// FilterTag 过滤包含指定上下文字段的日志，value为nil时只判断字段是否存在
+func (l *Logs) FilterTag(key string, value interface{}) *Logs {
+	return l.Filter(func(e Entry) bool {
+		v, ok := e.Tags[key]
+		return ok && (value == nil || assert.ObjectsAreEqualValues(value, v))
+	})
+}
+
+// AssertLogged 断言记录了指定级别和消息的日志
+func (l *Logs) AssertLogged(t testing.TB, level zapcore.Level, msg string) bool {
+	t.Helper()
+
+	if l.FilterLevel(level).FilterMessage(msg).Len() > 0 {
+		return true
+	}
+
+	return assert.Fail(t, "log not found", "level: %s, message: %q\nlogged: %s", level, msg, l.dump())
+}
+
+// AssertNotLogged 断言未记录指定级别和消息的日志
+func (l *Logs) AssertNotLogged(t testing.TB, level zapcore.Level, msg string) bool {
+	t.Helper()
+
+	if l.FilterLevel(level).FilterMessage(msg).Len() == 0 {
+		return true
+	}
+
+	return assert.Fail(t, "unexpected log found", "level: %s, message: %q", level, msg)
+}
+
+// AssertLen 断言日志条数
+func (l *Logs) AssertLen(t testing.TB, n int) bool {
+	t.Helper()
+
+	if l.Len() == n {
+		return true
+	}
+
+	return assert.Fail(t, "unexpected log count", "expected: %d, actual: %d\nlogged: %s", n, l.Len(), l.dump())
+}
+
+// add 添加日志
+func (l *Logs) add(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, e)
+}
+
+// dump 格式化全部日志，用于断言失败时输出
+func (l *Logs) dump() string {
+	var b strings.Builder
+	for _, e := range l.All() {
+		b.WriteString("\n\t[" + e.Level.String() + "] " + e.Message)
+	}
+
+	return b.String()
+}
+
+// NewCore 新建捕获日志的zap core，level为空时捕获全部级别
+func NewCore(level ...zapcore.Level) (zapcore.Core, *Logs) {
+	var enab zapcore.LevelEnabler = zapcore.DebugLevel
+	if len(level) > 0 {
+		enab = level[0]
+	}
+
+	logs := &Logs{}
+	return &core{LevelEnabler: enab, logs: logs}, logs
+}
+
+// New 新建捕获日志的日志记录器
+func New(level ...zapcore.Level) (logging.Logger, *Logs) {
+	c, logs := NewCore(level...)
+	return xzap.Wrap(zap.New(c)), logs
+}
+
+// Replace 替换xzap全局日志记录器并在测试结束后恢复，
+// 通过xzap.WithContext和xzap.ModuleWithContext等获取的日志记录器均被捕获
+func Replace(t testing.TB, level ...zapcore.Level) *Logs {
+	c, logs := NewCore(level...)
+	t.Cleanup(xzap.ReplaceLogger(zap.New(c)))
+
+	return logs
+}
+
+// core 捕获日志的zap core
+type core struct {
+	zapcore.LevelEnabler
+	logs    *Logs
+	context []zapcore.Field
+}
+
+// With 添加上下文字段
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{
+		LevelEnabler: c.LevelEnabler,
+		logs:         c.logs,
+		context:      append(append([]zapcore.Field(nil), c.context...), fields...),
+	}
+}
+
+// Check 判断日志条目是否需要记录
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write 捕获日志
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.logs.add(Entry{
+		Level:   ent.Level,
+		Time:    ent.Time,
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Tags:    fieldsMap(c.context),
+		Fields:  fieldsMap(fields),
+	})
+
+	return nil
+}
+
+// Sync 刷新缓冲区
+func (c *core) Sync() error {
+	return nil
+}
+
+// fieldsMap 将字段转换为map
+func fieldsMap(fields []zapcore.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return enc.Fields
+}
@@ -0,0 +1,46 @@
+package xzaptest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	logging "cxqi/common/logger"
+	"cxqi/common/logger/xzap"
+)
+
+func TestNew(t *testing.T) {
+	l, logs := New(zapcore.InfoLevel)
+	l.Debugf("debug")
+	l.With("k", "v").Infow("info", "a", 1)
+	l.Errorf("error %d", 1)
+
+	logs.AssertLen(t, 2)
+	logs.AssertLogged(t, zapcore.InfoLevel, "info")
+	logs.AssertNotLogged(t, zapcore.DebugLevel, "debug")
+	assert.Equal(t, []string{"info", "error 1"}, logs.Messages())
+	assert.Equal(t, 1, logs.FilterTag("k", "v").FilterField("a", 1).Len())
+	assert.Equal(t, 1, logs.FilterLevelAtLeast(zapcore.WarnLevel).Len())
+
+	assert.Len(t, logs.TakeAll(), 2)
+	assert.Equal(t, 0, logs.Len())
+}
+
+func TestReplace(t *testing.T) {
+	logs := Replace(t)
+
+	ctx := logging.SetInContext(context.Background(), logging.NewTags().Set(logging.TagUserId, int64(1000)))
+	xzap.WithContext(ctx).Warnw("context warn", "code", 1)
+	xzap.SlowWithContext(ctx, "xzaptest").Warnf("slow")
+
+	logs.AssertLogged(t, zapcore.WarnLevel, "context warn")
+	assert.Equal(t, 2, logs.FilterTag(logging.TagUserId, 1000).Len())
+	assert.Equal(t, 1, logs.FilterLogger(xzap.SlowLoggerName).FilterMessageContains("slo").Len())
+
+	e := logs.All()[0]
+	v, ok := e.Field("code")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), v)
+}
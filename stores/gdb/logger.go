@@ -7,17 +7,32 @@ import (
 	"time"
 
 	"github.com/go-stack/stack"
+	"go.uber.org/zap/zapcore"
 	"gorm.io/gorm/logger"
 
+	"cxqi/common/logger/redact"
 	"cxqi/common/logger/xzap"
 )
 
 const (
-	traceInfo  = "%s [%.3fms] [rows:%v] %s"
-	traceWarn  = "%s %s [%.3fms] [rows:%v] %s"
-	traceError = "%s %s [%.3fms] [rows:%v] %s"
+	// LogModule 日志模块名称，可通过xzap.SetLevel在运行时覆盖gorm日志级别
+	LogModule = "gdb"
+
+	traceMsg      = "sql trace"
+	traceSlowMsg  = "slow sql"
+	traceErrorMsg = "sql error"
+
+	fileKey          = "file"
+	elapsedKey       = "elapsed_ms"
+	rowsKey          = "rows"
+	sqlKey           = "sql"
+	slowThresholdKey = "slow_threshold"
 )
 
+func init() {
+	xzap.RegisterModule(LogModule)
+}
+
 // Logger 日志记录器
 type Logger struct {
 	LogLevel      logger.LogLevel
@@ -38,60 +53,75 @@ func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
 
 // Info Info日志记录
 func (l *Logger) Info(ctx context.Context, msg string, data ...interface{}) {
-	if l.LogLevel >= logger.Info {
-		xzap.WithContext(ctx).Infof(msg, data...)
+	if l.logLevel() >= logger.Info {
+		xzap.ModuleWithContext(ctx, LogModule).Infof(msg, data...)
 	}
 }
 
 // Warn Warn日志记录
 func (l *Logger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	if l.LogLevel >= logger.Warn {
-		xzap.WithContext(ctx).Warnf(msg, data...)
+	if l.logLevel() >= logger.Warn {
+		xzap.ModuleWithContext(ctx, LogModule).Warnf(msg, data...)
 	}
 }
 
 // Error Error日志记录
 func (l *Logger) Error(ctx context.Context, msg string, data ...interface{}) {
-	if l.LogLevel >= logger.Error {
-		xzap.WithContext(ctx).Errorf(msg, data...)
+	if l.logLevel() >= logger.Error {
+		xzap.ModuleWithContext(ctx, LogModule).Errorf(msg, data...)
 	}
 }
 
-// Trace Trace日志记录
+// Trace Trace日志记录，文件位置、耗时、影响行数和sql语句以字段形式记录，慢sql写入慢日志
 func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	if l.LogLevel > logger.Silent {
-		log := xzap.WithContext(ctx)
+	if logLevel := l.logLevel(); logLevel > logger.Silent {
+		log := xzap.ModuleWithContext(ctx, LogModule)
 		elapsed := time.Since(begin)
 
 		switch {
-		case err != nil && l.LogLevel >= logger.Error:
-			sql, rows := fc()
-			if rows == -1 {
-				log.Errorf(traceError, FileWithLineNum(), err, float64(elapsed.Nanoseconds())/1e6, "-", sql)
-			} else {
-				log.Errorf(traceError, FileWithLineNum(), err, float64(elapsed.Nanoseconds())/1e6, rows, sql)
-			}
-		case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
-			sql, rows := fc()
-			slowLog := fmt.Sprintf("Slow SQL Greater Than %v", l.SlowThreshold)
-			if rows == -1 {
-				// log.Slowf(traceWarn, FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/1e6, "-", sql)
-				log.Warnf(traceWarn, FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/1e6, "-", sql)
-			} else {
-				// log.Slowf(traceWarn, FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/1e6, rows, sql)
-				log.Warnf(traceWarn, FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/1e6, rows, sql)
-			}
-		case l.LogLevel == logger.Info:
-			sql, rows := fc()
-			if rows == -1 {
-				log.Infof(traceInfo, FileWithLineNum(), float64(elapsed.Nanoseconds())/1e6, "-", sql)
-			} else {
-				log.Infof(traceInfo, FileWithLineNum(), float64(elapsed.Nanoseconds())/1e6, rows, sql)
-			}
+		case err != nil && logLevel >= logger.Error:
+			log.Errorw(traceErrorMsg, append(traceFields(FileWithLineNum(), elapsed, fc), "error", err)...)
+		case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && logLevel >= logger.Warn:
+			xzap.SlowWithContext(ctx, LogModule).Warnw(traceSlowMsg, append(traceFields(FileWithLineNum(), elapsed, fc), slowThresholdKey, l.SlowThreshold)...)
+		case logLevel == logger.Info:
+			log.Infow(traceMsg, traceFields(FileWithLineNum(), elapsed, fc)...)
 		}
 	}
 }
 
+// logLevel 获取生效的日志级别，模块日志级别被覆盖时以覆盖的级别为准
+func (l *Logger) logLevel() logger.LogLevel {
+	level, ok := xzap.ModuleLevel(LogModule)
+	if !ok {
+		return l.LogLevel
+	}
+
+	switch {
+	case level <= zapcore.InfoLevel:
+		return logger.Info
+	case level == zapcore.WarnLevel:
+		return logger.Warn
+	case level == zapcore.ErrorLevel:
+		return logger.Error
+	default:
+		return logger.Silent
+	}
+}
+
+// traceFields 获取Trace日志字段，sql语句中敏感列的绑定值被脱敏
+func traceFields(file string, elapsed time.Duration, fc func() (string, int64)) []interface{} {
+	sql, rows := fc()
+	fields := []interface{}{
+		fileKey, file,
+		elapsedKey, float64(elapsed.Nanoseconds()) / 1e6,
+	}
+	if rows != -1 {
+		fields = append(fields, rowsKey, rows)
+	}
+
+	return append(fields, sqlKey, redact.SQL(sql))
+}
+
 // FileWithLineNum 获取调用堆栈信息
 func FileWithLineNum() string {
 	cs := stack.Trace().TrimBelow(stack.Caller(2)).TrimRuntime()
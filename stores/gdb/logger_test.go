@@ -6,10 +6,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
 	"gorm.io/gorm/logger"
+
+	"cxqi/common/logger/xzap"
+	"cxqi/common/logger/xzap/xzaptest"
 )
 
 func TestNewLogger(t *testing.T) {
+	logs := xzaptest.Replace(t)
+
 	l := NewLogger(logger.Info, 200*time.Millisecond)
 	l.Info(context.Background(), "info test, p1: %v, p2: %v, p3: %v", 1, 2, 3)
 	l.Warn(context.Background(), "warn test, p1: %v, p2: %v, p3: %v", 1, 2, 3)
@@ -17,10 +24,38 @@ func TestNewLogger(t *testing.T) {
 	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "test sql", 1 }, nil)
 	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "test err sql", 0 }, errors.New("err sql"))
 
+	logs.AssertLen(t, 5)
+	logs.AssertLogged(t, zapcore.InfoLevel, "info test, p1: 1, p2: 2, p3: 3")
+	logs.AssertLogged(t, zapcore.InfoLevel, traceMsg)
+	logs.AssertLogged(t, zapcore.ErrorLevel, traceErrorMsg)
+	assert.Equal(t, 1, logs.FilterField(sqlKey, "test sql").FilterField(rowsKey, 1).Len())
+	assert.Equal(t, 1, logs.FilterField(sqlKey, "test err sql").FilterField("error", "err sql").Len())
+	logs.TakeAll()
+
 	l = NewLogger(logger.Error, 200*time.Millisecond)
 	l.Info(context.Background(), "info test, p1: %v, p2: %v, p3: %v", 1, 2, 3)
 	l.Warn(context.Background(), "warn test, p1: %v, p2: %v, p3: %v", 1, 2, 3)
 	l.Error(context.Background(), "error test, p1: %v, p2: %v, p3: %v", 1, 2, 3)
 	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "test sql", 1 }, nil)
 	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "test err sql", 0 }, errors.New("err sql"))
+
+	logs.AssertLen(t, 2)
+	logs.AssertLogged(t, zapcore.ErrorLevel, "error test, p1: 1, p2: 2, p3: 3")
+	logs.AssertLogged(t, zapcore.ErrorLevel, traceErrorMsg)
+}
+
+func TestLogger_TraceSlow(t *testing.T) {
+	logs := xzaptest.Replace(t)
+
+	l := NewLogger(logger.Warn, 200*time.Millisecond)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "fast sql", 1 }, nil)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) {
+		return "SELECT * FROM `users` WHERE `password` = 'abc'", 1
+	}, nil)
+
+	logs.AssertLen(t, 1)
+	slow := logs.FilterLogger(xzap.SlowLoggerName).FilterMessage(traceSlowMsg)
+	slow.AssertLen(t, 1)
+	assert.Equal(t, 1, slow.FilterField(sqlKey, "SELECT * FROM `users` WHERE `password` = '******'").Len())
+	assert.Equal(t, 1, slow.FilterField(slowThresholdKey, 200*time.Millisecond).Len())
 }
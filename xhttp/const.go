@@ -57,6 +57,9 @@ const (
 	// HeaderGWErrorMessage 自定义网关请求头：X-GW-Error-Message
 	HeaderGWErrorMessage = "X-GW-Error-Message"
 
+	// HeaderGWErrorGRPCCode 自定义网关请求头：X-GW-Error-Grpc-Code，业务错误对应的grpc状态码
+	HeaderGWErrorGRPCCode = "X-GW-Error-Grpc-Code"
+
 	// ApplicationForm  应用类型：x-www-form-urlencoded
 	ApplicationForm = "application/x-www-form-urlencoded"
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"cxqi/common/errcode"
+	"fmt"
 	"io/ioutil"
 	"mime/multipart"
 	"net"
@@ -15,7 +16,7 @@ import (
 
 	"cxqi/common/logger/xzap"
 
-	"cxqi/commonn/kit/validator"
+	"cxqi/common/kit/validator"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -41,6 +42,12 @@ type Reponse struct {
 
 	//return data
 	Data interface{} `json:"data" extensions:"x-order=003"`
+
+	//structured error details, such as field violations and retry delay
+	Details *errcode.Details `json:"details,omitempty" extensions:"x-order=004"`
+
+	//errors of the failed items of batch operations
+	Errors []errcode.ItemErr `json:"errors,omitempty" extensions:"x-order=005"`
 }
 
 //GetTraceId get link tracking id
@@ -52,6 +59,7 @@ func GetTraceId(ctx context.Context) string {
 	return ""
 }
 
+// WriteHeader 写入业务错误响应头，包括业务状态码、错误信息和对应的grpc状态码
 func WriteHeader(w http.ResponseWriter, err ...error) {
 	var ee error
 	if len(err) > 0 {
@@ -62,29 +70,44 @@ func WriteHeader(w http.ResponseWriter, err ...error) {
 
 	w.Header().Set(HeaderGWErrorCode, convert.ToString(e.Code()))
 	w.Header().Set(HeaderGWErrorMessage, url.QueryEscape(e.Error()))
+	w.Header().Set(HeaderGWErrorGRPCCode, convert.ToString(int(e.GRPCCode())))
 }
 
-// Error 错误响应返回
+// Error 错误响应返回，错误信息按请求的语言本地化，
+// 服务端错误以error级别记录日志，携带原因的客户端错误以warn级别记录日志
 func Error(c *gin.Context, err error) {
 	ctx := c.Request.Context()
 	e := errcode.ParseErr(err)
-	if e == errcode.ErrUnexpected || e == errcode.ErrCustom {
-		xzap.WithContext(ctx).Errorf("request handle err, code: %d, err: %+v", e.Code(), err)
+	switch {
+	case e.HTTPCode() >= http.StatusInternalServerError || errors.Is(e, errcode.ErrUnexpected) || e == errcode.ErrCustom:
+		xzap.WithContext(ctx).Errorw("request handle err", "code", e.Code(), "error", fmt.Sprintf("%+v", err))
+	case e.Unwrap() != nil:
+		// 客户端错误属于预期内的错误，仅以warn级别记录其原因，避免写入错误日志
+		xzap.WithContext(ctx).Warnw("request handle err", "code", e.Code(), "error", fmt.Sprintf("%+v", err))
 	}
 
+	locale := errcode.LocaleFromRequest(c.Request)
+	e = e.Localize(locale)
 	WriteHeader(c.Writer, e)
-	c.JSON(e.HTTPCode(), &Reponse{
+
+	resp := &Reponse{
 		TraceId: GetTraceId(ctx),
 		Code:    e.Code(),
 		Msg:     e.Error(),
 		Data:    nil,
-	})
+		Details: e.Details(),
+	}
+	var m *errcode.MultiErr
+	if errors.As(err, &m) {
+		resp.Errors = m.Localize(locale).Items()
+	}
+	c.JSON(e.HTTPCode(), resp)
 }
 
 // Parse 请求体解析
 func Parse(r *http.Request, v interface{}) error {
 	// if err := httpx.Parse(r, v); err != nil {
-	// 	xzap.WithContext(r.Context()).Errorf("request parse err, err: %s", formatStr(err.Error(), halfShowLen))
+	// 	xzap.WithContext(r.Context()).Errorf("request parse err, err: %s", FormatStr(err.Error(), halfShowLen))
 	// 	return errcode.ErrInvalidParams
 	// }
 
@@ -99,7 +122,7 @@ func Parse(r *http.Request, v interface{}) error {
 func ParseForm(r *http.Request, v interface{}) error {
 	// if err := httpx.ParseForm(r, v); err != nil {
 	// 	xzap.WithContext(r.Context()).Errorf("request parse form err, err: %s",
-	// 		formatStr(err.Error(), halfShowLen))
+	// 		FormatStr(err.Error(), halfShowLen))
 	// 	return errcode.ErrInvalidParams
 	// }
 
@@ -242,7 +265,8 @@ func isLoopback(f net.Flags) bool {
 	return f&net.FlagLoopback == net.FlagLoopback
 }
 
-func formatStr(s string, halfShowLen int) string {
+// FormatStr 截断超过2倍halfShowLen长度的字符串，只保留首尾部分
+func FormatStr(s string, halfShowLen int) string {
 	if length := len(s); length > halfShowLen*2 {
 		return s[:halfShowLen] + " ...... " + s[length-halfShowLen-1:]
 	}
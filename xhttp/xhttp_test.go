@@ -1 +1,118 @@
 package xhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	"cxqi/common/errcode"
+	"cxqi/common/logger/xzap/xzaptest"
+)
+
+func TestError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := xzaptest.Replace(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errors.New("db down"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "7777", w.Header().Get(HeaderGWErrorCode))
+	assert.Equal(t, "13", w.Header().Get(HeaderGWErrorGRPCCode))
+	logs.AssertLogged(t, zapcore.ErrorLevel, "request handle err")
+	assert.Equal(t, 1, logs.FilterField("code", errcode.ErrUnexpected.Code()).Len())
+
+	// 业务错误不记录日志
+	logs.TakeAll()
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errcode.ErrInvalidParams)
+	logs.AssertLen(t, 0)
+}
+
+func TestError_Wrapped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := xzaptest.Replace(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errors.WithMessage(errcode.Wrap(errors.New("token is expired"), errcode.ErrTokenExpire), "parse token"))
+
+	// 响应只包含业务错误信息，日志包含完整的错误链
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "10004", w.Header().Get(HeaderGWErrorCode))
+	assert.Equal(t, "16", w.Header().Get(HeaderGWErrorGRPCCode))
+	assert.NotContains(t, w.Body.String(), "token is expired")
+	logs.AssertLogged(t, zapcore.WarnLevel, "request handle err")
+	logs.AssertNotLogged(t, zapcore.ErrorLevel, "request handle err")
+	msg, _ := logs.All()[0].Field("error")
+	assert.Contains(t, msg, "caused by: token is expired")
+
+	// 携带原因的服务端错误以error级别记录
+	logs.TakeAll()
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errcode.Wrap(errors.New("db down"), errcode.NewErr(20001, "Storage error", http.StatusServiceUnavailable)))
+	logs.AssertLogged(t, zapcore.ErrorLevel, "request handle err")
+}
+
+func TestError_Details(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errcode.ErrInvalidParams.WithFieldViolation("name", "required").WithRetryDelay(time.Second))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"trace_id":"","code":10002,"msg":"Parameter is illegal","data":null,`+
+		`"details":{"field_violations":[{"field":"name","description":"required"}],"retry_delay_ms":1000}}`, w.Body.String())
+
+	// 无详情时不返回details字段
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	Error(c, errcode.ErrInvalidParams)
+	assert.NotContains(t, w.Body.String(), "details")
+}
+
+func TestError_Localize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	Error(c, errcode.ErrInvalidParams)
+
+	assert.JSONEq(t, `{"trace_id":"","code":10002,"msg":"参数非法","data":null}`, w.Body.String())
+	assert.Equal(t, "%E5%8F%82%E6%95%B0%E9%9D%9E%E6%B3%95", w.Header().Get(HeaderGWErrorMessage))
+}
+
+func TestError_MultiErr(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := errcode.NewMultiErr()
+	m.Add(0, errcode.ErrInvalidParams.WithFieldViolation("name", "required"))
+	m.Add(2, errcode.ErrTokenExpire)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/batch", nil)
+	Error(c, errors.Wrap(m, "batch update"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "10004", w.Header().Get(HeaderGWErrorCode))
+	assert.JSONEq(t, `{"trace_id":"","code":10004,"msg":"Expired token","data":null,"errors":[`+
+		`{"index":0,"code":10002,"msg":"Parameter is illegal","details":{"field_violations":[{"field":"name","description":"required"}]}},`+
+		`{"index":2,"code":10004,"msg":"Expired token"}]}`, w.Body.String())
+}